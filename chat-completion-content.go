@@ -0,0 +1,200 @@
+package gopenai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	mimeTypeImagePrefix = "image/"
+	dataURLTpl          = "data:%s;base64,%s"
+)
+
+// ChatCompletionContentPartType is an enum type representing
+// the type of a part of a message's content.
+type ChatCompletionContentPartType string
+
+// ChatCompletionContentPartType enum values
+const (
+	ChatCompletionContentPartTypeText       ChatCompletionContentPartType = "text"
+	ChatCompletionContentPartTypeImageURL   ChatCompletionContentPartType = "image_url"
+	ChatCompletionContentPartTypeInputAudio ChatCompletionContentPartType = "input_audio"
+)
+
+// ImageDetail is an enum type representing the level of
+// detail the model uses to process an image.
+type ImageDetail string
+
+// ImageDetail enum values
+const (
+	ImageDetailAuto ImageDetail = "auto"
+	ImageDetailLow  ImageDetail = "low"
+	ImageDetailHigh ImageDetail = "high"
+)
+
+// InputAudioFormat is an enum type representing the format of input audio.
+type InputAudioFormat string
+
+// InputAudioFormat enum values
+const (
+	InputAudioFormatWAV InputAudioFormat = "wav"
+	InputAudioFormatMP3 InputAudioFormat = "mp3"
+)
+
+// ChatCompletionContentPart represents a part of the content of a
+// message, which is either text, an image or audio.
+type ChatCompletionContentPart struct {
+	// Type is the type of the part.
+	Type ChatCompletionContentPartType `json:"type"`
+	// Text is the text of a text part.
+	Text string `json:"text,omitempty"`
+	// ImageURL is the image of an image part.
+	ImageURL *ChatCompletionImageURL `json:"image_url,omitempty"`
+	// InputAudio is the audio of an audio part.
+	InputAudio *ChatCompletionInputAudio `json:"input_audio,omitempty"`
+}
+
+// ChatCompletionImageURL represents an image sent to the model.
+type ChatCompletionImageURL struct {
+	// URL is either the URL of the image or a data URL
+	// holding the base64 encoded image.
+	URL string `json:"url"`
+	// Detail is the level of detail the model uses to process the image.
+	Detail ImageDetail `json:"detail,omitempty"`
+}
+
+// ChatCompletionInputAudio represents audio sent to the model.
+type ChatCompletionInputAudio struct {
+	// Data is the base64 encoded audio.
+	Data string `json:"data"`
+	// Format is the format of the audio.
+	Format InputAudioFormat `json:"format"`
+}
+
+// NewTextContentPart returns a text content part.
+func NewTextContentPart(text string) ChatCompletionContentPart {
+	return ChatCompletionContentPart{
+		Type: ChatCompletionContentPartTypeText,
+		Text: text,
+	}
+}
+
+// NewImageURLContentPart returns an image content part
+// referencing the image at the given URL.
+func NewImageURLContentPart(url string, detail ImageDetail) ChatCompletionContentPart {
+	return ChatCompletionContentPart{
+		Type: ChatCompletionContentPartTypeImageURL,
+		ImageURL: &ChatCompletionImageURL{
+			URL:    url,
+			Detail: detail,
+		},
+	}
+}
+
+// NewImageContentPartFromReader returns an image content part holding
+// the image read from r as a data URL. The MIME type of the image is
+// sniffed from its content. It returns ErrUnsupportedImageType if the
+// content is not an image.
+func NewImageContentPartFromReader(r io.Reader, detail ImageDetail) (ChatCompletionContentPart, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ChatCompletionContentPart{}, err
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, mimeTypeImagePrefix) {
+		return ChatCompletionContentPart{}, fmt.Errorf("%w: %s", ErrUnsupportedImageType, mimeType)
+	}
+
+	return NewImageURLContentPart(dataURL(mimeType, data), detail), nil
+}
+
+// NewImageContentPartFromFile returns an image content part holding the
+// image at the given file path as a data URL, as built by
+// NewImageContentPartFromReader.
+func NewImageContentPartFromFile(filePath string, detail ImageDetail) (ChatCompletionContentPart, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ChatCompletionContentPart{}, err
+	}
+	defer file.Close()
+
+	return NewImageContentPartFromReader(file, detail)
+}
+
+// NewInputAudioContentPartFromReader returns an audio content part
+// holding the audio in the given format read from r.
+func NewInputAudioContentPartFromReader(r io.Reader, format InputAudioFormat) (ChatCompletionContentPart, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ChatCompletionContentPart{}, err
+	}
+
+	return ChatCompletionContentPart{
+		Type: ChatCompletionContentPartTypeInputAudio,
+		InputAudio: &ChatCompletionInputAudio{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	}, nil
+}
+
+func dataURL(mimeType string, data []byte) string {
+	return fmt.Sprintf(dataURLTpl, mimeType, base64.StdEncoding.EncodeToString(data))
+}
+
+// marshalMessageContent returns the JSON encoding of the content of a
+// message. Content parts are encoded as an array unless there is only a
+// single text part, which is encoded as a string like plain content.
+// Empty content is encoded as null when nullable is true.
+func marshalMessageContent(content string, parts []ChatCompletionContentPart, nullable bool) (json.RawMessage, error) {
+	if len(parts) == 0 {
+		if content == "" && nullable {
+			return json.RawMessage("null"), nil
+		}
+
+		return json.Marshal(content)
+	}
+
+	if content != "" {
+		return nil, errors.New("message has both Content and ContentParts")
+	}
+
+	if len(parts) == 1 && parts[0].Type == ChatCompletionContentPartTypeText {
+		return json.Marshal(parts[0].Text)
+	}
+
+	return json.Marshal(parts)
+}
+
+// unmarshalMessageContent decodes the content of a message
+// which is either a string, an array of parts or null.
+func unmarshalMessageContent(data json.RawMessage) (string, []ChatCompletionContentPart, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return "", nil, nil
+	}
+
+	if data[0] != '[' {
+		var content *string
+		if err := json.Unmarshal(data, &content); err != nil || content == nil {
+			return "", nil, err
+		}
+
+		return *content, nil, nil
+	}
+
+	var parts []ChatCompletionContentPart
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return "", nil, err
+	}
+
+	return "", parts, nil
+}
@@ -0,0 +1,103 @@
+package gopenai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletionMessageContentPartsJSON(t *testing.T) {
+	testCases := []struct {
+		name         string
+		message      ChatCompletionMessage
+		expectedJSON string
+	}{
+		{
+			name: "single text part",
+			message: ChatCompletionMessage{
+				Role:         ChatCompletionMessageRoleUser,
+				ContentParts: []ChatCompletionContentPart{NewTextContentPart("hi")},
+			},
+			expectedJSON: `{"role":"user","content":"hi"}`,
+		},
+		{
+			name: "text and image parts",
+			message: ChatCompletionMessage{
+				Role: ChatCompletionMessageRoleUser,
+				ContentParts: []ChatCompletionContentPart{
+					NewTextContentPart("What is in this image?"),
+					NewImageURLContentPart("https://example.com/cat.png", ImageDetailLow),
+				},
+			},
+			expectedJSON: `{"role":"user","content":[` +
+				`{"type":"text","text":"What is in this image?"},` +
+				`{"type":"image_url","image_url":{"url":"https://example.com/cat.png","detail":"low"}}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.message)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expectedJSON, string(data))
+		})
+	}
+}
+
+func TestChatCompletionMessageUnmarshalContent(t *testing.T) {
+	var message ChatCompletionMessage
+
+	require.NoError(t, json.Unmarshal([]byte(`{"role":"user","content":"hi"}`), &message))
+	assert.Equal(t, ChatCompletionMessage{Role: ChatCompletionMessageRoleUser, Content: "hi"}, message)
+
+	message = ChatCompletionMessage{}
+	require.NoError(t, json.Unmarshal([]byte(`{"role":"user","content":[`+
+		`{"type":"text","text":"Listen"},{"type":"input_audio","input_audio":{"data":"AAA=","format":"wav"}}]}`), &message))
+	assert.Equal(t, ChatCompletionMessage{
+		Role: ChatCompletionMessageRoleUser,
+		ContentParts: []ChatCompletionContentPart{
+			NewTextContentPart("Listen"),
+			{
+				Type:       ChatCompletionContentPartTypeInputAudio,
+				InputAudio: &ChatCompletionInputAudio{Data: "AAA=", Format: InputAudioFormatWAV},
+			},
+		},
+	}, message)
+	assert.Equal(t, "Listen", message.Text())
+
+	message = ChatCompletionMessage{}
+	require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":null}`), &message))
+	assert.Equal(t, ChatCompletionMessage{Role: ChatCompletionMessageRoleAssistant}, message)
+}
+
+func TestChatCompletionMessageMarshalContentAndParts(t *testing.T) {
+	_, err := json.Marshal(ChatCompletionMessage{
+		Content:      "hi",
+		ContentParts: []ChatCompletionContentPart{NewTextContentPart("hi")},
+	})
+	assert.Error(t, err)
+}
+
+func TestNewImageContentPart(t *testing.T) {
+	part, err := NewImageContentPartFromFile("./.fixture/test-image.png", ImageDetailHigh)
+	require.NoError(t, err)
+	assert.Equal(t, ChatCompletionContentPartTypeImageURL, part.Type)
+	assert.Equal(t, ImageDetailHigh, part.ImageURL.Detail)
+	assert.Equal(t, "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg==", part.ImageURL.URL)
+
+	_, err = NewImageContentPartFromFile("./.fixture/test-file.jsonl", ImageDetailAuto)
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+
+	_, err = NewImageContentPartFromFile("/invalid/path/to/image.png", ImageDetailAuto)
+	assert.Error(t, err)
+
+	_, err = NewImageContentPartFromReader(strings.NewReader("not an image"), ImageDetailAuto)
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+
+	part, err = NewImageContentPartFromReader(strings.NewReader("GIF89a"), ImageDetailAuto)
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/gif;base64,R0lGODlh", part.ImageURL.URL)
+}
@@ -0,0 +1,466 @@
+package gopenai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/psyb0t/gopenai/tokenizer"
+)
+
+const (
+	// Every reply is primed with <|start|>assistant<|message|>.
+	replyPrimingTokens = 3
+
+	legacyChatModel            = "gpt-3.5-turbo-0301"
+	legacyTokensPerMessage     = 4
+	legacyTokensPerName        = -1
+	defaultTokensPerMessage    = 3
+	defaultTokensPerName       = 1
+	fineTunedModelPrefix       = "ft:"
+	dataURLPrefix              = "data:"
+	dataURLBase64Separator     = ";base64,"
+	imageLowDetailTokens       = 85
+	imageTileTokens            = 170
+	imageTileSize              = 512
+	imageMaxSize               = 2048
+	imageShortSideSize         = 768
+	imageUnknownSizeTokens     = imageLowDetailTokens + 8*imageTileTokens
+	toolsEndTokens             = 12
+	toolInitTokens             = 10
+	toolInitTokensO200K        = 7
+	toolPropertiesInitTokens   = 3
+	toolPropertyKeyTokens      = 3
+	toolPropertyEnumInitTokens = -3
+	toolPropertyEnumItemTokens = 3
+)
+
+var (
+	// ErrUnknownContextWindow is an error that indicates the
+	// size of the context window of a model is not known.
+	ErrUnknownContextWindow = errors.New("unknown context window")
+	// ErrContextWindowExceeded is an error that indicates the messages
+	// do not fit in the context window, even after trimming them.
+	ErrContextWindowExceeded = errors.New("context window exceeded")
+)
+
+// imageConfigDecoders holds the functions decoding the size of the
+// images whose size is counted, keyed by MIME type. They are called
+// directly rather than registered with the image package, so that
+// importing this package does not change what image.Decode supports.
+var imageConfigDecoders = map[string]func(io.Reader) (image.Config, error){
+	"image/gif":  gif.DecodeConfig,
+	"image/jpeg": jpeg.DecodeConfig,
+	"image/png":  png.DecodeConfig,
+}
+
+// modelContextWindows holds the size of the context windows
+// of models, keyed by model name or model name prefix.
+var modelContextWindows = map[string]int{
+	"gpt-5":                  400000,
+	"gpt-4.1":                1047576,
+	"gpt-4o":                 128000,
+	"chatgpt-4o":             128000,
+	"gpt-4-turbo":            128000,
+	"gpt-4-1106":             128000,
+	"gpt-4-0125":             128000,
+	"gpt-4-32k":              32768,
+	"gpt-4":                  8192,
+	"gpt-3.5-turbo-instruct": 4096,
+	"gpt-3.5-turbo":          16385,
+	"o1-mini":                128000,
+	"o1-preview":             128000,
+	"o1":                     200000,
+	"o3":                     200000,
+	"o4-mini":                200000,
+}
+
+// modelContextWindowPrefixes holds the keys of modelContextWindows,
+// longest first so that the most specific prefix wins.
+var modelContextWindowPrefixes = func() []string {
+	prefixes := make([]string, 0, len(modelContextWindows))
+	for prefix := range modelContextWindows {
+		prefixes = append(prefixes, prefix)
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+
+		return prefixes[i] < prefixes[j]
+	})
+
+	return prefixes
+}()
+
+// ModelContextWindow returns the size of the context window of the
+// given model in tokens. Fine-tuned models and dated snapshots are
+// supported. It returns false if the model is not known.
+func ModelContextWindow(model string) (int, bool) {
+	model = strings.TrimPrefix(model, fineTunedModelPrefix)
+	for _, prefix := range modelContextWindowPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return modelContextWindows[prefix], true
+		}
+	}
+
+	return 0, false
+}
+
+// CountChatTokens returns the number of prompt tokens used by the given
+// messages and tool definitions when sent to the given model, including
+// the tokens priming the reply. The count follows the accounting rules
+// published by OpenAI, so it is an estimate that may be off by a few
+// tokens. Image parts are counted from their size when they are embedded
+// as PNG, JPEG or GIF data URLs and as the largest possible image
+// otherwise. Audio parts are not counted.
+func CountChatTokens(model string, messages []ChatCompletionMessage, tools ...ChatCompletionTool) (int, error) {
+	counter, err := newChatTokenCounter(model)
+	if err != nil {
+		return 0, err
+	}
+
+	count := replyPrimingTokens
+	for _, message := range messages {
+		count += counter.countMessage(message)
+	}
+
+	toolsCount, err := counter.countTools(tools)
+	if err != nil {
+		return 0, err
+	}
+
+	return count + toolsCount, nil
+}
+
+// FitToContextOptions holds the options of FitToContext.
+type FitToContextOptions struct {
+	// ContextWindow is the size of the context window in tokens.
+	// Defaults to the context window of the model.
+	ContextWindow int
+	// ReservedTokens is the number of tokens of the
+	// context window reserved for the reply.
+	ReservedTokens int
+	// Tools are the tools sent along with the messages,
+	// whose definitions use up part of the context window.
+	Tools []ChatCompletionTool
+	// Summarize, if set, is called with the messages trimmed from the
+	// conversation and returns a message summarizing them, which is kept
+	// in their place. It is called again with more messages if the
+	// conversation still does not fit along with the summary.
+	Summarize func(ctx context.Context, messages []ChatCompletionMessage) (ChatCompletionMessage, error)
+}
+
+// FitToContext returns the messages trimmed so that they fit in the
+// context window of the given model along with the reserved tokens.
+// The leading system messages are always kept and the oldest messages
+// after them are trimmed first. An assistant message calling tools is
+// trimmed along with the tool messages holding the results. It returns
+// ErrContextWindowExceeded if the messages do not fit even when only
+// the system messages and the last message are kept. The given slice
+// is not modified.
+func FitToContext(ctx context.Context, model string, messages []ChatCompletionMessage, opts FitToContextOptions) ([]ChatCompletionMessage, error) {
+	if opts.ContextWindow <= 0 {
+		contextWindow, ok := ModelContextWindow(model)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownContextWindow, model)
+		}
+
+		opts.ContextWindow = contextWindow
+	}
+
+	counter, err := newChatTokenCounter(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return counter.fit(ctx, messages, opts)
+}
+
+type chatTokenCounter struct {
+	encoding         *tokenizer.Encoding
+	tokensPerMessage int
+	tokensPerName    int
+}
+
+func newChatTokenCounter(model string) (chatTokenCounter, error) {
+	encoding, err := tokenizer.EncodingForModel(model)
+	if err != nil {
+		return chatTokenCounter{}, err
+	}
+
+	return newChatTokenCounterWithEncoding(model, encoding), nil
+}
+
+func newChatTokenCounterWithEncoding(model string, encoding *tokenizer.Encoding) chatTokenCounter {
+	if model == legacyChatModel {
+		return chatTokenCounter{
+			encoding:         encoding,
+			tokensPerMessage: legacyTokensPerMessage,
+			tokensPerName:    legacyTokensPerName,
+		}
+	}
+
+	return chatTokenCounter{
+		encoding:         encoding,
+		tokensPerMessage: defaultTokensPerMessage,
+		tokensPerName:    defaultTokensPerName,
+	}
+}
+
+func (c chatTokenCounter) countMessage(message ChatCompletionMessage) int {
+	count := c.tokensPerMessage +
+		c.encoding.Count(string(message.Role)) +
+		c.encoding.Count(message.Content) +
+		c.encoding.Count(message.Refusal)
+
+	if message.Name != "" {
+		count += c.encoding.Count(message.Name) + c.tokensPerName
+	}
+
+	for _, part := range message.ContentParts {
+		switch part.Type {
+		case ChatCompletionContentPartTypeText:
+			count += c.encoding.Count(part.Text)
+		case ChatCompletionContentPartTypeImageURL:
+			if part.ImageURL != nil {
+				count += countImageTokens(*part.ImageURL)
+			}
+		}
+	}
+
+	for _, toolCall := range message.ToolCalls {
+		count += c.encoding.Count(toolCall.Function.Name) + c.encoding.Count(toolCall.Function.Arguments)
+	}
+
+	return count
+}
+
+// toolParameters holds the parts of the JSON Schema
+// of tool parameters that are counted as tokens.
+type toolParameters struct {
+	Properties map[string]struct {
+		Type        json.RawMessage `json:"type"`
+		Description string          `json:"description"`
+		Enum        []interface{}   `json:"enum"`
+	} `json:"properties"`
+}
+
+// countTools returns the number of tokens used by the given tool
+// definitions, following the rules published by OpenAI.
+func (c chatTokenCounter) countTools(tools []ChatCompletionTool) (int, error) {
+	if len(tools) == 0 {
+		return 0, nil
+	}
+
+	initTokens := toolInitTokens
+	if c.encoding.Name() == tokenizer.O200KBase {
+		initTokens = toolInitTokensO200K
+	}
+
+	count := toolsEndTokens
+	for _, tool := range tools {
+		function := tool.Function
+		count += initTokens + c.encoding.Count(function.Name+":"+strings.TrimSuffix(function.Description, "."))
+
+		params, err := decodeToolParameters(function.Parameters)
+		if err != nil {
+			return 0, fmt.Errorf("could not decode the parameters of tool %s: %w", function.Name, err)
+		}
+
+		if len(params.Properties) == 0 {
+			continue
+		}
+
+		count += toolPropertiesInitTokens
+		for name, property := range params.Properties {
+			count += toolPropertyKeyTokens
+
+			if len(property.Enum) > 0 {
+				count += toolPropertyEnumInitTokens
+				for _, item := range property.Enum {
+					count += toolPropertyEnumItemTokens + c.encoding.Count(fmt.Sprint(item))
+				}
+			}
+
+			line := name + ":" + propertyType(property.Type) + ":" + strings.TrimSuffix(property.Description, ".")
+			count += c.encoding.Count(line)
+		}
+	}
+
+	return count, nil
+}
+
+// fit implements FitToContext.
+func (c chatTokenCounter) fit(ctx context.Context, messages []ChatCompletionMessage, opts FitToContextOptions) ([]ChatCompletionMessage, error) {
+	budget := opts.ContextWindow - opts.ReservedTokens
+
+	toolsCount, err := c.countTools(opts.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := 0
+	for pinned < len(messages) && messages[pinned].Role == ChatCompletionMessageRoleSystem {
+		pinned++
+	}
+
+	head, rest := messages[:pinned], messages[pinned:]
+
+	// suffixCounts[i] is the number of tokens used by rest[i:]
+	suffixCounts := make([]int, len(rest)+1)
+	for i := len(rest) - 1; i >= 0; i-- {
+		suffixCounts[i] = suffixCounts[i+1] + c.countMessage(rest[i])
+	}
+
+	baseCount := replyPrimingTokens + toolsCount
+	for _, message := range head {
+		baseCount += c.countMessage(message)
+	}
+
+	start := 0
+	for baseCount+suffixCounts[start] > budget {
+		if start = nextMessageGroup(rest, start); start >= len(rest) {
+			return nil, ErrContextWindowExceeded
+		}
+	}
+
+	result := append([]ChatCompletionMessage(nil), head...)
+	if start == 0 || opts.Summarize == nil {
+		return append(result, rest[start:]...), nil
+	}
+
+	for {
+		summary, err := opts.Summarize(ctx, rest[:start])
+		if err != nil {
+			return nil, fmt.Errorf("could not summarize the trimmed messages: %w", err)
+		}
+
+		if baseCount+c.countMessage(summary)+suffixCounts[start] <= budget {
+			result = append(result, summary)
+
+			return append(result, rest[start:]...), nil
+		}
+
+		if start = nextMessageGroup(rest, start); start >= len(rest) {
+			return nil, ErrContextWindowExceeded
+		}
+	}
+}
+
+// nextMessageGroup returns the index of the message following the
+// group of messages starting at i, which holds the message at i and
+// the tool messages answering its tool calls.
+func nextMessageGroup(messages []ChatCompletionMessage, i int) int {
+	i++
+	for i < len(messages) && messages[i].Role == ChatCompletionMessageRoleTool {
+		i++
+	}
+
+	return i
+}
+
+func decodeToolParameters(parameters interface{}) (toolParameters, error) {
+	params := toolParameters{}
+	if parameters == nil {
+		return params, nil
+	}
+
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return params, err
+	}
+
+	if err := json.Unmarshal(data, &params); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// propertyType returns the type of a JSON Schema property,
+// which is either a type name or an array of type names.
+func propertyType(data json.RawMessage) string {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return name
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err == nil {
+		return strings.Join(names, ",")
+	}
+
+	return ""
+}
+
+// countImageTokens returns the number of tokens used by an image,
+// following the rules published by OpenAI: low detail images use a
+// fixed number of tokens, other images are scaled to fit in a 2048px
+// square then down to 768px on their shortest side and use a number of
+// tokens per 512px tile. The size of images that are not embedded as
+// data URLs is unknown, so the largest possible number of tokens is used.
+func countImageTokens(imageURL ChatCompletionImageURL) int {
+	if imageURL.Detail == ImageDetailLow {
+		return imageLowDetailTokens
+	}
+
+	config, ok := decodeDataURLImageConfig(imageURL.URL)
+	if !ok || config.Width <= 0 || config.Height <= 0 {
+		return imageUnknownSizeTokens
+	}
+
+	width, height := float64(config.Width), float64(config.Height)
+	if longest := math.Max(width, height); longest > imageMaxSize {
+		width, height = width*imageMaxSize/longest, height*imageMaxSize/longest
+	}
+
+	if shortest := math.Min(width, height); shortest > imageShortSideSize {
+		width, height = width*imageShortSideSize/shortest, height*imageShortSideSize/shortest
+	}
+
+	tiles := math.Ceil(width/imageTileSize) * math.Ceil(height/imageTileSize)
+
+	return imageLowDetailTokens + int(tiles)*imageTileTokens
+}
+
+func decodeDataURLImageConfig(url string) (image.Config, bool) {
+	if !strings.HasPrefix(url, dataURLPrefix) {
+		return image.Config{}, false
+	}
+
+	i := strings.Index(url, dataURLBase64Separator)
+	if i < 0 {
+		return image.Config{}, false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(url[i+len(dataURLBase64Separator):])
+	if err != nil {
+		return image.Config{}, false
+	}
+
+	decodeConfig, ok := imageConfigDecoders[http.DetectContentType(data)]
+	if !ok {
+		return image.Config{}, false
+	}
+
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Config{}, false
+	}
+
+	return config, true
+}
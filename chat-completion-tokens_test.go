@@ -0,0 +1,365 @@
+package gopenai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/psyb0t/gopenai/tokenizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChatTokenCounter returns a counter using an encoding where
+// every byte is a token, so that text uses as many tokens as bytes.
+func newTestChatTokenCounter(t *testing.T, model string) chatTokenCounter {
+	t.Helper()
+
+	ranks := strings.Builder{}
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&ranks, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+
+	encoding, err := tokenizer.NewEncoding(tokenizer.CL100KBase, strings.NewReader(ranks.String()))
+	require.NoError(t, err)
+
+	return newChatTokenCounterWithEncoding(model, encoding)
+}
+
+func newTestImageDataURL(t *testing.T, width, height int) string {
+	t.Helper()
+
+	return newTestImageDataURLOfType(t, "image/png", width, height)
+}
+
+func newTestImageDataURLOfType(t *testing.T, mimeType string, width, height int) string {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	data := &bytes.Buffer{}
+
+	switch mimeType {
+	case "image/gif":
+		require.NoError(t, gif.Encode(data, img, nil))
+	case "image/jpeg":
+		require.NoError(t, jpeg.Encode(data, img, nil))
+	default:
+		require.NoError(t, png.Encode(data, img))
+	}
+
+	return dataURL(mimeType, data.Bytes())
+}
+
+func TestChatTokenCounterCountMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		model    string
+		message  ChatCompletionMessage
+		expected int
+	}{
+		{
+			name:     "text",
+			model:    "gpt-4o",
+			message:  ChatCompletionMessage{Role: ChatCompletionMessageRoleSystem, Content: "Be brief"},
+			expected: 3 + 6 + 8,
+		},
+		{
+			name:     "name",
+			model:    "gpt-4o",
+			message:  ChatCompletionMessage{Role: ChatCompletionMessageRoleUser, Content: "Hi", Name: "bob"},
+			expected: 3 + 4 + 2 + 3 + 1,
+		},
+		{
+			name:     "legacy model name",
+			model:    "gpt-3.5-turbo-0301",
+			message:  ChatCompletionMessage{Role: ChatCompletionMessageRoleUser, Content: "Hi", Name: "bob"},
+			expected: 4 + 4 + 2 + 3 - 1,
+		},
+		{
+			name:  "tool calls",
+			model: "gpt-4o",
+			message: ChatCompletionMessage{
+				Role:      ChatCompletionMessageRoleAssistant,
+				ToolCalls: []ChatCompletionToolCall{newTestToolCall("call_1", "f", "{}")},
+			},
+			expected: 3 + 9 + 1 + 2,
+		},
+		{
+			name:  "parts",
+			model: "gpt-4o",
+			message: ChatCompletionMessage{
+				Role: ChatCompletionMessageRoleUser,
+				ContentParts: []ChatCompletionContentPart{
+					NewTextContentPart("What?"),
+					NewImageURLContentPart("https://example.com/cat.png", ImageDetailLow),
+				},
+			},
+			expected: 3 + 4 + 5 + 85,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, newTestChatTokenCounter(t, tc.model).countMessage(tc.message))
+		})
+	}
+}
+
+func TestChatTokenCounterCountTools(t *testing.T) {
+	counter := newTestChatTokenCounter(t, "gpt-4")
+
+	count, err := counter.countTools(nil)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = counter.countTools([]ChatCompletionTool{
+		NewFunctionTool("get_weather", "Get the weather.", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"city": map[string]interface{}{"type": "string", "description": "The city."},
+				"unit": map[string]interface{}{"type": "string", "enum": []string{"c", "f"}},
+			},
+		}),
+	})
+	require.NoError(t, err)
+
+	expected := 12 + // end
+		10 + len("get_weather:Get the weather") +
+		3 + // properties
+		3 + len("city:string:The city") +
+		3 - 3 + (3 + 1) + (3 + 1) + len("unit:string:")
+	assert.Equal(t, expected, count)
+}
+
+func TestCountImageTokens(t *testing.T) {
+	testCases := []struct {
+		name     string
+		imageURL ChatCompletionImageURL
+		expected int
+	}{
+		{
+			name:     "low detail",
+			imageURL: ChatCompletionImageURL{URL: "https://example.com/cat.png", Detail: ImageDetailLow},
+			expected: 85,
+		},
+		{
+			name:     "unknown size",
+			imageURL: ChatCompletionImageURL{URL: "https://example.com/cat.png", Detail: ImageDetailHigh},
+			expected: 85 + 8*170,
+		},
+		{
+			name:     "small",
+			imageURL: ChatCompletionImageURL{URL: newTestImageDataURL(t, 100, 600)},
+			expected: 85 + 2*170,
+		},
+		{
+			name:     "scaled to the short side",
+			imageURL: ChatCompletionImageURL{URL: newTestImageDataURL(t, 1024, 1024), Detail: ImageDetailHigh},
+			expected: 85 + 4*170,
+		},
+		{
+			name:     "scaled to fit",
+			imageURL: ChatCompletionImageURL{URL: newTestImageDataURL(t, 4096, 2048), Detail: ImageDetailAuto},
+			expected: 85 + 6*170,
+		},
+		{
+			name:     "jpeg",
+			imageURL: ChatCompletionImageURL{URL: newTestImageDataURLOfType(t, "image/jpeg", 100, 600)},
+			expected: 85 + 2*170,
+		},
+		{
+			name:     "gif",
+			imageURL: ChatCompletionImageURL{URL: newTestImageDataURLOfType(t, "image/gif", 1024, 1024)},
+			expected: 85 + 4*170,
+		},
+		{
+			name:     "unsupported format",
+			imageURL: ChatCompletionImageURL{URL: dataURL("image/webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "))},
+			expected: 85 + 8*170,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, countImageTokens(tc.imageURL))
+		})
+	}
+}
+
+func TestChatTokenCounterFit(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		{Role: ChatCompletionMessageRoleSystem, Content: "S"},        // 10
+		{Role: ChatCompletionMessageRoleUser, Content: "1111111111"}, // 17
+		{Role: ChatCompletionMessageRoleAssistant, ToolCalls: []ChatCompletionToolCall{ // 15
+			newTestToolCall("call_1", "f", "{}"),
+		}},
+		{Role: ChatCompletionMessageRoleTool, Content: "ok", ToolCallID: "call_1"}, // 9
+		{Role: ChatCompletionMessageRoleUser, Content: "2"},                        // 8
+	}
+
+	summary := ChatCompletionMessage{Role: ChatCompletionMessageRoleSystem, Content: "sum"} // 12
+
+	testCases := []struct {
+		name              string
+		opts              FitToContextOptions
+		expected          []ChatCompletionMessage
+		expectedSummarize int
+		expectedErr       error
+	}{
+		{
+			name:     "fits",
+			opts:     FitToContextOptions{ContextWindow: 62},
+			expected: messages,
+		},
+		{
+			name:     "trimmed with tool results",
+			opts:     FitToContextOptions{ContextWindow: 50, ReservedTokens: 10},
+			expected: []ChatCompletionMessage{messages[0], messages[4]},
+		},
+		{
+			name: "summarized",
+			opts: FitToContextOptions{ContextWindow: 50, ReservedTokens: 10, Summarize: func(
+				_ context.Context, trimmed []ChatCompletionMessage,
+			) (ChatCompletionMessage, error) {
+				return summary, nil
+			}},
+			expected:          []ChatCompletionMessage{messages[0], summary, messages[4]},
+			expectedSummarize: 3,
+		},
+		{
+			name:        "exceeded",
+			opts:        FitToContextOptions{ContextWindow: 20},
+			expectedErr: ErrContextWindowExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			summarized := 0
+			if summarize := tc.opts.Summarize; summarize != nil {
+				tc.opts.Summarize = func(ctx context.Context, trimmed []ChatCompletionMessage) (ChatCompletionMessage, error) {
+					summarized = len(trimmed)
+
+					return summarize(ctx, trimmed)
+				}
+			}
+
+			fitted, err := newTestChatTokenCounter(t, "gpt-4o").fit(context.Background(), messages, tc.opts)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, fitted)
+			assert.Equal(t, tc.expectedSummarize, summarized)
+		})
+	}
+}
+
+// TestCountChatTokens checks the counts against the prompt_tokens billed
+// by the API for the examples of the OpenAI cookbook on counting tokens.
+func TestCountChatTokens(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		{Role: ChatCompletionMessageRoleSystem, Content: "You are a helpful, pattern-following assistant that translates corporate jargon into plain English."},
+		{Role: ChatCompletionMessageRoleSystem, Name: "example_user", Content: "New synergies will help drive top-line growth."},
+		{Role: ChatCompletionMessageRoleSystem, Name: "example_assistant", Content: "Things working well together will increase revenue."},
+		{Role: ChatCompletionMessageRoleSystem, Name: "example_user", Content: "Let's circle back when we have more bandwidth to touch base on opportunities for increased leverage."},
+		{Role: ChatCompletionMessageRoleSystem, Name: "example_assistant", Content: "Let's talk later when we're less busy about how to do better."},
+		{Role: ChatCompletionMessageRoleUser, Content: "This late pivot means we don't have time to boil the ocean for the client deliverable."},
+	}
+
+	toolMessages := []ChatCompletionMessage{
+		{Role: ChatCompletionMessageRoleSystem, Content: "You are a helpful assistant that can answer to questions about the weather."},
+		{Role: ChatCompletionMessageRoleUser, Content: "What's the weather like in San Francisco?"},
+	}
+
+	tools := []ChatCompletionTool{
+		NewFunctionTool("get_current_weather", "Get the current weather in a given location", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"location": map[string]interface{}{
+					"type":        "string",
+					"description": "The city and state, e.g. San Francisco, CA",
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"description": "The unit of temperature to return",
+					"enum":        []string{"celsius", "fahrenheit"},
+				},
+			},
+			"required": []string{"location"},
+		}),
+	}
+
+	testCases := []struct {
+		model         string
+		messages      []ChatCompletionMessage
+		tools         []ChatCompletionTool
+		expectedCount int
+	}{
+		{model: "gpt-3.5-turbo-0613", messages: messages, expectedCount: 129},
+		{model: "gpt-4-0613", messages: messages, expectedCount: 129},
+		{model: "gpt-4o", messages: messages, expectedCount: 124},
+		{model: "gpt-4o-mini", messages: messages, expectedCount: 124},
+		{model: "gpt-3.5-turbo", messages: toolMessages, tools: tools, expectedCount: 105},
+		{model: "gpt-4", messages: toolMessages, tools: tools, expectedCount: 105},
+		{model: "gpt-4o", messages: toolMessages, tools: tools, expectedCount: 101},
+		{model: "gpt-4o-mini", messages: toolMessages, tools: tools, expectedCount: 101},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %d tools", tc.model, len(tc.tools)), func(t *testing.T) {
+			count, err := CountChatTokens(tc.model, tc.messages, tc.tools...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCount, count)
+		})
+	}
+}
+
+func TestFitToContextWithModelEncoding(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		{Role: ChatCompletionMessageRoleSystem, Content: "You are a helpful assistant."},
+		{Role: ChatCompletionMessageRoleUser, Content: strings.Repeat("hello ", 100)},
+		{Role: ChatCompletionMessageRoleAssistant, Content: "Hi!"},
+		{Role: ChatCompletionMessageRoleUser, Content: "How are you?"},
+	}
+
+	fitted, err := FitToContext(context.Background(), "gpt-4o", messages, FitToContextOptions{ContextWindow: 50})
+	require.NoError(t, err)
+	assert.Equal(t, []ChatCompletionMessage{messages[0], messages[2], messages[3]}, fitted)
+
+	count, err := CountChatTokens("gpt-4o", fitted)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, count, 50)
+}
+
+func TestFitToContextUnknownContextWindow(t *testing.T) {
+	_, err := FitToContext(context.Background(), "unknown", nil, FitToContextOptions{})
+	assert.ErrorIs(t, err, ErrUnknownContextWindow)
+}
+
+func TestModelContextWindow(t *testing.T) {
+	testCases := []struct {
+		model      string
+		expected   int
+		expectedOK bool
+	}{
+		{model: "gpt-4o-mini-2024-07-18", expected: 128000, expectedOK: true},
+		{model: "ft:gpt-4o-mini-2024-07-18:org::abc123", expected: 128000, expectedOK: true},
+		{model: "gpt-4-32k-0613", expected: 32768, expectedOK: true},
+		{model: "gpt-4", expected: 8192, expectedOK: true},
+		{model: "o1-mini", expected: 128000, expectedOK: true},
+		{model: "unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.model, func(t *testing.T) {
+			contextWindow, ok := ModelContextWindow(tc.model)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expected, contextWindow)
+		})
+	}
+}
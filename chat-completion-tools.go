@@ -0,0 +1,174 @@
+package gopenai
+
+import "encoding/json"
+
+// ChatCompletionToolType is an enum type representing the type of a tool.
+type ChatCompletionToolType string
+
+// ChatCompletionToolType enum values
+const (
+	ChatCompletionToolTypeFunction ChatCompletionToolType = "function"
+)
+
+// ChatCompletionTool represents a tool the model may call.
+type ChatCompletionTool struct {
+	// Type is the type of the tool. Only functions are supported.
+	Type ChatCompletionToolType `json:"type"`
+	// Function is the definition of the function.
+	Function ChatCompletionFunction `json:"function"`
+}
+
+// ChatCompletionFunction represents the definition of a function tool.
+type ChatCompletionFunction struct {
+	// Name is the name of the function.
+	Name string `json:"name"`
+	// Description describes what the function does, used by the model
+	// to choose when and how to call the function.
+	Description string `json:"description,omitempty"`
+	// Parameters is the JSON Schema object describing the
+	// parameters the function accepts.
+	Parameters interface{} `json:"parameters,omitempty"`
+	// Strict enables strict schema adherence when
+	// generating the function call.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// ChatCompletionToolCall represents a call to a tool made by the model.
+type ChatCompletionToolCall struct {
+	// ID is the identifier of the tool call, referenced by the
+	// ToolCallID of the message holding the tool's result.
+	ID string `json:"id"`
+	// Type is the type of the tool.
+	Type ChatCompletionToolType `json:"type"`
+	// Function is the function the model called.
+	Function ChatCompletionFunctionCall `json:"function"`
+}
+
+// ChatCompletionFunctionCall represents the name and
+// arguments of a function called by the model.
+type ChatCompletionFunctionCall struct {
+	// Name is the name of the function to call.
+	Name string `json:"name"`
+	// Arguments are the arguments to call the function with, as
+	// generated by the model in JSON format. The model does not always
+	// generate valid JSON, so they should be validated before use.
+	Arguments string `json:"arguments"`
+}
+
+// ChatCompletionToolCallDelta represents the part of a tool call
+// that was generated since the previous chunk of a stream.
+type ChatCompletionToolCallDelta struct {
+	// Index is the position of the tool call in the message.
+	Index int `json:"index"`
+	// ID is the identifier of the tool call, sent with its first delta.
+	ID string `json:"id,omitempty"`
+	// Type is the type of the tool, sent with its first delta.
+	Type ChatCompletionToolType `json:"type,omitempty"`
+	// Function holds the function name, sent with the first delta,
+	// and the next fragment of the arguments.
+	Function ChatCompletionFunctionCall `json:"function"`
+}
+
+// ChatCompletionToolChoiceMode is an enum type representing
+// whether and how the model should call tools.
+type ChatCompletionToolChoiceMode string
+
+// ChatCompletionToolChoiceMode enum values
+const (
+	ChatCompletionToolChoiceModeNone     ChatCompletionToolChoiceMode = "none"
+	ChatCompletionToolChoiceModeAuto     ChatCompletionToolChoiceMode = "auto"
+	ChatCompletionToolChoiceModeRequired ChatCompletionToolChoiceMode = "required"
+)
+
+// ChatCompletionToolChoice controls which tool, if any, the model calls.
+// It is encoded as the mode string unless a function name is set,
+// in which case the model is forced to call that function.
+type ChatCompletionToolChoice struct {
+	// Mode is whether and how the model should call tools.
+	Mode ChatCompletionToolChoiceMode
+	// FunctionName is the name of the function the model must call.
+	FunctionName string
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c ChatCompletionToolChoice) MarshalJSON() ([]byte, error) {
+	if c.FunctionName == "" {
+		return json.Marshal(c.Mode)
+	}
+
+	var choice struct {
+		Type     ChatCompletionToolType `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+
+	choice.Type = ChatCompletionToolTypeFunction
+	choice.Function.Name = c.FunctionName
+
+	return json.Marshal(choice)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *ChatCompletionToolChoice) UnmarshalJSON(data []byte) error {
+	var mode ChatCompletionToolChoiceMode
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*c = ChatCompletionToolChoice{Mode: mode}
+
+		return nil
+	}
+
+	var choice struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+
+	if err := json.Unmarshal(data, &choice); err != nil {
+		return err
+	}
+
+	*c = ChatCompletionToolChoice{FunctionName: choice.Function.Name}
+
+	return nil
+}
+
+// NewFunctionTool returns a function tool with the given name,
+// description and JSON Schema parameters.
+func NewFunctionTool(name, description string, parameters interface{}) ChatCompletionTool {
+	return ChatCompletionTool{
+		Type: ChatCompletionToolTypeFunction,
+		Function: ChatCompletionFunction{
+			Name:        name,
+			Description: description,
+			Parameters:  parameters,
+		},
+	}
+}
+
+// accumulateToolCalls merges the given tool call deltas into
+// toolCalls, concatenating the argument fragments of each call.
+func accumulateToolCalls(toolCalls []ChatCompletionToolCall, deltas []ChatCompletionToolCallDelta) []ChatCompletionToolCall {
+	for _, delta := range deltas {
+		for len(toolCalls) <= delta.Index {
+			toolCalls = append(toolCalls, ChatCompletionToolCall{})
+		}
+
+		toolCall := &toolCalls[delta.Index]
+		if delta.ID != "" {
+			toolCall.ID = delta.ID
+		}
+
+		if delta.Type != "" {
+			toolCall.Type = delta.Type
+		}
+
+		if delta.Function.Name != "" {
+			toolCall.Function.Name = delta.Function.Name
+		}
+
+		toolCall.Function.Arguments += delta.Function.Arguments
+	}
+
+	return toolCalls
+}
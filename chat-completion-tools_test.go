@@ -0,0 +1,135 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletionMessageMarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name         string
+		message      ChatCompletionMessage
+		expectedJSON string
+	}{
+		{
+			name:         "text content",
+			message:      ChatCompletionMessage{Role: ChatCompletionMessageRoleUser, Content: "hi"},
+			expectedJSON: `{"role":"user","content":"hi"}`,
+		},
+		{
+			name: "tool calls without content",
+			message: ChatCompletionMessage{
+				Role: ChatCompletionMessageRoleAssistant,
+				ToolCalls: []ChatCompletionToolCall{{
+					ID:       "call_1",
+					Type:     ChatCompletionToolTypeFunction,
+					Function: ChatCompletionFunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+				}},
+			},
+			expectedJSON: `{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function",` +
+				`"function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]}`,
+		},
+		{
+			name:         "tool result",
+			message:      ChatCompletionMessage{Role: ChatCompletionMessageRoleTool, Content: "sunny", ToolCallID: "call_1"},
+			expectedJSON: `{"role":"tool","content":"sunny","tool_call_id":"call_1"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.message)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expectedJSON, string(data))
+
+			var decoded ChatCompletionMessage
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tc.message, decoded)
+		})
+	}
+}
+
+func TestChatCompletionToolChoiceJSON(t *testing.T) {
+	testCases := []struct {
+		name         string
+		choice       ChatCompletionToolChoice
+		expectedJSON string
+	}{
+		{
+			name:         "mode",
+			choice:       ChatCompletionToolChoice{Mode: ChatCompletionToolChoiceModeRequired},
+			expectedJSON: `"required"`,
+		},
+		{
+			name:         "function",
+			choice:       ChatCompletionToolChoice{FunctionName: "get_weather"},
+			expectedJSON: `{"type":"function","function":{"name":"get_weather"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.choice)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expectedJSON, string(data))
+
+			var decoded ChatCompletionToolChoice
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tc.choice, decoded)
+		})
+	}
+}
+
+func TestChatCompletionsCreateStreamToolCalls(t *testing.T) {
+	server := newTestStreamServer(t, ""+
+		"data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":null,"+
+		"\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\","+
+		"\"function\":{\"name\":\"get_weather\",\"arguments\":\"\"}}]}}]}\n\n"+
+		"data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":["+
+		"{\"index\":0,\"function\":{\"arguments\":\"{\\\"city\\\":\"}},"+
+		"{\"index\":1,\"id\":\"call_2\",\"type\":\"function\",\"function\":{\"name\":\"get_time\",\"arguments\":\"{}\"}}]}}]}\n\n"+
+		"data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":["+
+		"{\"index\":0,\"function\":{\"arguments\":\"\\\"Paris\\\"}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\n"+
+		"data: [DONE]\n\n")
+
+	api := New(Config{BaseURL: server.URL}).ChatCompletions()
+
+	stream, err := api.CreateStream(context.Background(), ChatCompletionParams{
+		Model: "gpt-4o",
+		Tools: []ChatCompletionTool{NewFunctionTool("get_weather", "", nil)},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var acc ChatCompletionAccumulator
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		acc.Accumulate(chunk)
+	}
+
+	completion := acc.ChatCompletion()
+	require.Len(t, completion.Choices, 1)
+	assert.Equal(t, "tool_calls", completion.Choices[0].FinishReason)
+	assert.Equal(t, []ChatCompletionToolCall{
+		{
+			ID:       "call_1",
+			Type:     ChatCompletionToolTypeFunction,
+			Function: ChatCompletionFunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		},
+		{
+			ID:       "call_2",
+			Type:     ChatCompletionToolTypeFunction,
+			Function: ChatCompletionFunctionCall{Name: "get_time", Arguments: `{}`},
+		},
+	}, completion.Choices[0].Message.ToolCalls)
+}
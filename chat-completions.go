@@ -1,9 +1,11 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 const chatCompletionsAPIEndpoint = "/chat/completions"
@@ -15,16 +17,89 @@ type LogitBias map[string]interface{}
 // completion request, including the model to use, the input
 // messages, and various completion settings.
 type ChatCompletionParams struct {
-	Model            string                  `json:"model"`
-	Messages         []ChatCompletionMessage `json:"messages"`
-	Temperature      float64                 `json:"temperature,omitempty"`
-	TopP             float64                 `json:"top_p,omitempty"`
-	N                int                     `json:"n,omitempty"`
-	Stop             string                  `json:"stop,omitempty"`
-	PresencePenalty  float64                 `json:"presence_penalty,omitempty"`
-	FrequencyPenalty float64                 `json:"frequency_penalty,omitempty"`
-	LogitBias        LogitBias               `json:"logit_bias,omitempty"`
-	User             string                  `json:"user,omitempty"`
+	Model               string                        `json:"model"`
+	Messages            []ChatCompletionMessage       `json:"messages"`
+	Temperature         *float64                      `json:"temperature,omitempty"`
+	TopP                *float64                      `json:"top_p,omitempty"`
+	N                   int                           `json:"n,omitempty"`
+	Stop                []string                      `json:"stop,omitempty"`
+	MaxTokens           int                           `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int                           `json:"max_completion_tokens,omitempty"`
+	PresencePenalty     *float64                      `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64                      `json:"frequency_penalty,omitempty"`
+	LogitBias           LogitBias                     `json:"logit_bias,omitempty"`
+	Logprobs            bool                          `json:"logprobs,omitempty"`
+	TopLogprobs         *int                          `json:"top_logprobs,omitempty"`
+	Seed                *int                          `json:"seed,omitempty"`
+	User                string                        `json:"user,omitempty"`
+	Stream              bool                          `json:"stream,omitempty"`
+	StreamOptions       *ChatCompletionStreamOptions  `json:"stream_options,omitempty"`
+	Tools               []ChatCompletionTool          `json:"tools,omitempty"`
+	ToolChoice          *ChatCompletionToolChoice     `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool                         `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat      *ChatCompletionResponseFormat `json:"response_format,omitempty"`
+	ServiceTier         ServiceTier                   `json:"service_tier,omitempty"`
+	Store               bool                          `json:"store,omitempty"`
+	Metadata            map[string]string             `json:"metadata,omitempty"`
+	ReasoningEffort     ReasoningEffort               `json:"reasoning_effort,omitempty"`
+}
+
+// ChatCompletionStreamOptions holds the options of a streamed chat completion.
+type ChatCompletionStreamOptions struct {
+	// IncludeUsage makes the API send a last chunk, without any
+	// choices, holding the token usage of the whole request.
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ServiceTier is an enum type representing the
+// processing tier used to serve a request.
+type ServiceTier string
+
+// ServiceTier enum values
+const (
+	ServiceTierAuto     ServiceTier = "auto"
+	ServiceTierDefault  ServiceTier = "default"
+	ServiceTierFlex     ServiceTier = "flex"
+	ServiceTierPriority ServiceTier = "priority"
+)
+
+// ReasoningEffort is an enum type representing how much
+// reasoning models reason before responding.
+type ReasoningEffort string
+
+// ReasoningEffort enum values
+const (
+	ReasoningEffortMinimal ReasoningEffort = "minimal"
+	ReasoningEffortLow     ReasoningEffort = "low"
+	ReasoningEffortMedium  ReasoningEffort = "medium"
+	ReasoningEffortHigh    ReasoningEffort = "high"
+)
+
+// ChatCompletionResponseFormatType is an enum type representing
+// the format the model must generate its response in.
+type ChatCompletionResponseFormatType string
+
+// ChatCompletionResponseFormatType enum values
+const (
+	ChatCompletionResponseFormatTypeText       ChatCompletionResponseFormatType = "text"
+	ChatCompletionResponseFormatTypeJSONObject ChatCompletionResponseFormatType = "json_object"
+	ChatCompletionResponseFormatTypeJSONSchema ChatCompletionResponseFormatType = "json_schema"
+)
+
+// ChatCompletionResponseFormat specifies the format the
+// model must generate its response in.
+type ChatCompletionResponseFormat struct {
+	Type       ChatCompletionResponseFormatType        `json:"type"`
+	JSONSchema *ChatCompletionResponseFormatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ChatCompletionResponseFormatJSONSchema holds the JSON Schema the
+// response must match when using the json_schema response format.
+type ChatCompletionResponseFormatJSONSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Schema      interface{} `json:"schema"`
+	Strict      bool        `json:"strict,omitempty"`
 }
 
 // ChatCompletionMessageRole is an enum type representing the role
@@ -36,28 +111,268 @@ const (
 	ChatCompletionMessageRoleSystem    ChatCompletionMessageRole = "system"
 	ChatCompletionMessageRoleUser      ChatCompletionMessageRole = "user"
 	ChatCompletionMessageRoleAssistant ChatCompletionMessageRole = "assistant"
+	ChatCompletionMessageRoleTool      ChatCompletionMessageRole = "tool"
 )
 
-// ChatCompletionMessage represents a message in a chat conversation
+// ChatCompletionMessage represents a message in a chat conversation.
+// The content is either the plain text in Content or, for multimodal
+// messages, the parts in ContentParts. An empty content is encoded as null
+// for assistant messages that only hold tool calls or a refusal.
 type ChatCompletionMessage struct {
-	Role    ChatCompletionMessageRole `json:"role"`
-	Content string                    `json:"content"`
+	Role         ChatCompletionMessageRole   `json:"role"`
+	Name         string                      `json:"name,omitempty"`
+	Content      string                      `json:"content"`
+	ContentParts []ChatCompletionContentPart `json:"-"`
+	Refusal      string                      `json:"refusal,omitempty"`
+	ToolCalls    []ChatCompletionToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID   string                      `json:"tool_call_id,omitempty"`
+}
+
+// Text returns the text content of the message, joining
+// the text parts of multimodal messages.
+func (m ChatCompletionMessage) Text() string {
+	if len(m.ContentParts) == 0 {
+		return m.Content
+	}
+
+	texts := make([]string, 0, len(m.ContentParts))
+	for _, part := range m.ContentParts {
+		if part.Type == ChatCompletionContentPartTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
+	type message ChatCompletionMessage
+
+	nullable := len(m.ToolCalls) > 0 || m.Refusal != ""
+
+	content, err := marshalMessageContent(m.Content, m.ContentParts, nullable)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		message
+		Content json.RawMessage `json:"content"`
+	}{message(m), content})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *ChatCompletionMessage) UnmarshalJSON(data []byte) error {
+	type message ChatCompletionMessage
+
+	var decoded struct {
+		message
+		Content json.RawMessage `json:"content"`
+	}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	content, parts, err := unmarshalMessageContent(decoded.Content)
+	if err != nil {
+		return err
+	}
+
+	*m = ChatCompletionMessage(decoded.message)
+	m.Content = content
+	m.ContentParts = parts
+
+	return nil
 }
 
 // ChatCompletion represents a chat completion of a prompt generated by the API.
 type ChatCompletion struct {
-	ID      string                 `json:"id"`
-	Object  string                 `json:"object"`
-	Created int                    `json:"created"`
-	Choices []ChatCompletionChoice `json:"choices"`
-	Usage   TokenUsage             `json:"usage"`
+	ID                string                 `json:"id"`
+	Object            string                 `json:"object"`
+	Created           int                    `json:"created"`
+	Model             string                 `json:"model"`
+	SystemFingerprint string                 `json:"system_fingerprint,omitempty"`
+	ServiceTier       ServiceTier            `json:"service_tier,omitempty"`
+	Choices           []ChatCompletionChoice `json:"choices"`
+	Usage             TokenUsage             `json:"usage"`
+
+	raw json.RawMessage
+}
+
+// RawJSON returns the JSON the completion was decoded from, which
+// holds the fields of the response that are not mapped by the struct.
+// It is nil for completions that were not decoded from JSON.
+func (c ChatCompletion) RawJSON() json.RawMessage {
+	return c.raw
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *ChatCompletion) UnmarshalJSON(data []byte) error {
+	type completion ChatCompletion
+
+	if err := json.Unmarshal(data, (*completion)(c)); err != nil {
+		return err
+	}
+
+	c.raw = append(json.RawMessage(nil), data...)
+
+	return nil
 }
 
 // ChatCompletionChoice represents a single completion for a given prompt.
 type ChatCompletionChoice struct {
-	Index        int                   `json:"index"`
-	Message      ChatCompletionMessage `json:"message"`
-	FinishReason string                `json:"finish_reason"`
+	Index        int                     `json:"index"`
+	Message      ChatCompletionMessage   `json:"message"`
+	FinishReason string                  `json:"finish_reason"`
+	Logprobs     *ChatCompletionLogprobs `json:"logprobs,omitempty"`
+}
+
+// ChatCompletionLogprobs holds the log probabilities
+// of the tokens of a choice, when requested.
+type ChatCompletionLogprobs struct {
+	Content []ChatCompletionTokenLogprob `json:"content"`
+	Refusal []ChatCompletionTokenLogprob `json:"refusal"`
+}
+
+// ChatCompletionTokenLogprob holds the log probability of a generated
+// token and the most likely tokens at its position.
+type ChatCompletionTokenLogprob struct {
+	Token       string                     `json:"token"`
+	Logprob     float64                    `json:"logprob"`
+	Bytes       []int                      `json:"bytes"`
+	TopLogprobs []ChatCompletionTopLogprob `json:"top_logprobs"`
+}
+
+// ChatCompletionTopLogprob holds the log probability
+// of one of the most likely tokens at a position.
+type ChatCompletionTopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes"`
+}
+
+// ChatCompletionChunk represents a chunk of a chat
+// completion that is being streamed by the API.
+type ChatCompletionChunk struct {
+	ID                string                      `json:"id"`
+	Object            string                      `json:"object"`
+	Created           int                         `json:"created"`
+	Model             string                      `json:"model"`
+	SystemFingerprint string                      `json:"system_fingerprint,omitempty"`
+	ServiceTier       ServiceTier                 `json:"service_tier,omitempty"`
+	Choices           []ChatCompletionChunkChoice `json:"choices"`
+	// Usage is only set on the last chunk, when
+	// requested through the stream options.
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+// ChatCompletionChunkChoice represents the change to a single
+// completion carried by a ChatCompletionChunk.
+type ChatCompletionChunkChoice struct {
+	Index        int                     `json:"index"`
+	Delta        ChatCompletionDelta     `json:"delta"`
+	FinishReason string                  `json:"finish_reason"`
+	Logprobs     *ChatCompletionLogprobs `json:"logprobs,omitempty"`
+}
+
+// ChatCompletionDelta represents the part of a message
+// that was generated since the previous chunk.
+type ChatCompletionDelta struct {
+	Role      ChatCompletionMessageRole     `json:"role,omitempty"`
+	Content   string                        `json:"content,omitempty"`
+	Refusal   string                        `json:"refusal,omitempty"`
+	ToolCalls []ChatCompletionToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// ChatCompletionStream is a stream of chat completion chunks.
+// It must be closed when no longer needed.
+type ChatCompletionStream struct {
+	r *streamReader[ChatCompletionChunk]
+}
+
+// Recv returns the next chunk in the stream. It returns io.EOF once
+// the stream has ended and an *APIError if the API sends an error
+// event in the middle of the stream.
+func (s *ChatCompletionStream) Recv() (ChatCompletionChunk, error) {
+	return s.r.recv()
+}
+
+// Close closes the underlying connection of the stream.
+func (s *ChatCompletionStream) Close() error {
+	return s.r.close()
+}
+
+// ChatCompletionAccumulator rebuilds a ChatCompletion out of the
+// chunks of a stream. The zero value is ready to use.
+type ChatCompletionAccumulator struct {
+	completion ChatCompletion
+	choices    map[int]*ChatCompletionChoice
+}
+
+// Accumulate adds the given chunk to the completion being rebuilt.
+func (a *ChatCompletionAccumulator) Accumulate(chunk ChatCompletionChunk) {
+	if a.choices == nil {
+		a.choices = map[int]*ChatCompletionChoice{}
+	}
+
+	a.completion.ID = chunk.ID
+	a.completion.Object = chunk.Object
+	a.completion.Created = chunk.Created
+	a.completion.Model = chunk.Model
+	a.completion.SystemFingerprint = chunk.SystemFingerprint
+	a.completion.ServiceTier = chunk.ServiceTier
+
+	if chunk.Usage != nil {
+		a.completion.Usage = *chunk.Usage
+	}
+
+	for _, chunkChoice := range chunk.Choices {
+		choice, ok := a.choices[chunkChoice.Index]
+		if !ok {
+			choice = &ChatCompletionChoice{Index: chunkChoice.Index}
+			a.choices[chunkChoice.Index] = choice
+		}
+
+		if chunkChoice.Delta.Role != "" {
+			choice.Message.Role = chunkChoice.Delta.Role
+		}
+
+		choice.Message.Content += chunkChoice.Delta.Content
+		choice.Message.Refusal += chunkChoice.Delta.Refusal
+		choice.Message.ToolCalls = accumulateToolCalls(choice.Message.ToolCalls, chunkChoice.Delta.ToolCalls)
+
+		if chunkChoice.Logprobs != nil {
+			if choice.Logprobs == nil {
+				choice.Logprobs = &ChatCompletionLogprobs{}
+			}
+
+			choice.Logprobs.Content = append(choice.Logprobs.Content, chunkChoice.Logprobs.Content...)
+			choice.Logprobs.Refusal = append(choice.Logprobs.Refusal, chunkChoice.Logprobs.Refusal...)
+		}
+
+		if chunkChoice.FinishReason != "" {
+			choice.FinishReason = chunkChoice.FinishReason
+		}
+	}
+}
+
+// ChatCompletion returns the completion rebuilt from the
+// chunks accumulated so far, with its choices sorted by index.
+func (a *ChatCompletionAccumulator) ChatCompletion() ChatCompletion {
+	completion := a.completion
+	completion.Choices = make([]ChatCompletionChoice, 0, len(a.choices))
+
+	for _, choice := range a.choices {
+		completion.Choices = append(completion.Choices, *choice)
+	}
+
+	sort.Slice(completion.Choices, func(i, j int) bool {
+		return completion.Choices[i].Index < completion.Choices[j].Index
+	})
+
+	return completion
 }
 
 // ChatCompletionsAPI is an interface for creating completions.
@@ -65,6 +380,12 @@ type ChatCompletionsAPI interface {
 	// Create generates a new completion based on the given
 	// ChatCompletionParams and returns it as a ChatCompletion object.
 	Create(ChatCompletionParams) (ChatCompletion, error)
+	// CreateWithContext is like Create but uses the given
+	// context for the request.
+	CreateWithContext(context.Context, ChatCompletionParams) (ChatCompletion, error)
+	// CreateStream generates a new completion based on the given
+	// ChatCompletionParams and streams it as it is being generated.
+	CreateStream(context.Context, ChatCompletionParams) (*ChatCompletionStream, error)
 }
 
 type chatCompletionsAPI struct {
@@ -72,8 +393,12 @@ type chatCompletionsAPI struct {
 }
 
 func (api chatCompletionsAPI) Create(params ChatCompletionParams) (ChatCompletion, error) {
-	url := fmt.Sprintf("%s%s", baseURL, chatCompletionsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api chatCompletionsAPI) CreateWithContext(ctx context.Context, params ChatCompletionParams) (ChatCompletion, error) {
+	url := api.c.endpointURL(chatCompletionsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return ChatCompletion{}, err
 	}
@@ -85,3 +410,15 @@ func (api chatCompletionsAPI) Create(params ChatCompletionParams) (ChatCompletio
 
 	return response, nil
 }
+
+func (api chatCompletionsAPI) CreateStream(ctx context.Context, params ChatCompletionParams) (*ChatCompletionStream, error) {
+	params.Stream = true
+
+	url := api.c.endpointURL(chatCompletionsAPIEndpoint)
+	r, err := getStreamResponse[ChatCompletionChunk](ctx, api.c, url, http.MethodPost, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatCompletionStream{r: r}, nil
+}
@@ -0,0 +1,263 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStreamServer(t *testing.T, events string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Stream bool `json:"stream"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		assert.True(t, params.Stream)
+
+		w.Header().Set(headerNameContentType, "text/event-stream")
+		_, _ = io.WriteString(w, events)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestChatCompletionsCreateStream(t *testing.T) {
+	server := newTestStreamServer(t, ""+
+		"data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"choices\":"+
+		"[{\"index\":0,\"delta\":{\"role\":\"assistant\"},\"finish_reason\":null}]}\n\n"+
+		": ping\n\n"+
+		"data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"choices\":"+
+		"[{\"index\":1,\"delta\":{\"role\":\"assistant\",\"content\":\"Bye\"},\"finish_reason\":\"stop\"},"+
+		"{\"index\":0,\"delta\":{\"content\":\"Hello\"},\"finish_reason\":null}]}\n\n"+
+		"data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"choices\":"+
+		"[{\"index\":0,\"delta\":{\"content\":\" world\"},\"finish_reason\":\"stop\"}]}\n\n"+
+		"data: [DONE]\n\n")
+
+	api := New(Config{BaseURL: server.URL}).ChatCompletions()
+
+	stream, err := api.CreateStream(context.Background(), ChatCompletionParams{Model: "gpt-3.5-turbo"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var acc ChatCompletionAccumulator
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		acc.Accumulate(chunk)
+	}
+
+	expected := ChatCompletion{
+		ID:      "c1",
+		Object:  "chat.completion.chunk",
+		Created: 1,
+		Choices: []ChatCompletionChoice{
+			{
+				Index:        0,
+				Message:      ChatCompletionMessage{Role: ChatCompletionMessageRoleAssistant, Content: "Hello world"},
+				FinishReason: "stop",
+			},
+			{
+				Index:        1,
+				Message:      ChatCompletionMessage{Role: ChatCompletionMessageRoleAssistant, Content: "Bye"},
+				FinishReason: "stop",
+			},
+		},
+	}
+
+	assert.Equal(t, expected, acc.ChatCompletion())
+
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestChatCompletionsCreateStreamErrorEvent(t *testing.T) {
+	server := newTestStreamServer(t, ""+
+		"data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\n"+
+		"event: error\n"+
+		"data: {\"error\":{\"message\":\"overloaded\",\"type\":\"server_error\",\"code\":\"overloaded\"}}\n\n")
+
+	api := New(Config{BaseURL: server.URL}).ChatCompletions()
+
+	stream, err := api.CreateStream(context.Background(), ChatCompletionParams{Model: "gpt-3.5-turbo"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", chunk.Choices[0].Delta.Content)
+
+	_, err = stream.Recv()
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "overloaded", apiErr.Message)
+	assert.Equal(t, "server_error", apiErr.Type)
+}
+
+func TestChatCompletionParamsJSON(t *testing.T) {
+	params := ChatCompletionParams{
+		Model:               "o3-mini",
+		Messages:            []ChatCompletionMessage{{Role: ChatCompletionMessageRoleUser, Name: "bob", Content: "Hi"}},
+		Stop:                []string{"\n", "END"},
+		MaxCompletionTokens: 100,
+		Logprobs:            true,
+		TopLogprobs:         Int(2),
+		Seed:                Int(0),
+		Stream:              true,
+		StreamOptions:       &ChatCompletionStreamOptions{IncludeUsage: true},
+		ServiceTier:         ServiceTierFlex,
+		Store:               true,
+		Metadata:            map[string]string{"user": "bob"},
+		ReasoningEffort:     ReasoningEffortLow,
+	}
+
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	expected := `{
+		"model": "o3-mini",
+		"messages": [{"role": "user", "name": "bob", "content": "Hi"}],
+		"stop": ["\n", "END"],
+		"max_completion_tokens": 100,
+		"logprobs": true,
+		"top_logprobs": 2,
+		"seed": 0,
+		"stream": true,
+		"stream_options": {"include_usage": true},
+		"service_tier": "flex",
+		"store": true,
+		"metadata": {"user": "bob"},
+		"reasoning_effort": "low"
+	}`
+
+	assert.JSONEq(t, expected, string(data))
+}
+
+func TestChatCompletionsCreate(t *testing.T) {
+	response := `{
+		"id": "c1",
+		"object": "chat.completion",
+		"created": 1,
+		"model": "gpt-4o-2024-08-06",
+		"system_fingerprint": "fp_1",
+		"service_tier": "default",
+		"choices": [{
+			"index": 0,
+			"message": {"role": "assistant", "content": "Hi", "annotations": []},
+			"finish_reason": "stop",
+			"logprobs": {"content": [{"token": "Hi", "logprob": -0.5, "bytes": [72, 105], "top_logprobs": [
+				{"token": "Hi", "logprob": -0.5, "bytes": [72, 105]}
+			]}], "refusal": null}
+		}],
+		"usage": {
+			"prompt_tokens": 10,
+			"completion_tokens": 1,
+			"total_tokens": 11,
+			"prompt_tokens_details": {"cached_tokens": 8, "audio_tokens": 0},
+			"completion_tokens_details": {"reasoning_tokens": 0, "audio_tokens": 0,
+				"accepted_prediction_tokens": 0, "rejected_prediction_tokens": 0}
+		},
+		"unknown_field": "value"
+	}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, response)
+	}))
+	defer server.Close()
+
+	completion, err := New(Config{BaseURL: server.URL}).ChatCompletions().Create(ChatCompletionParams{Model: "gpt-4o"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gpt-4o-2024-08-06", completion.Model)
+	assert.Equal(t, "fp_1", completion.SystemFingerprint)
+	assert.Equal(t, ServiceTierDefault, completion.ServiceTier)
+	assert.Equal(t, &PromptTokensDetails{CachedTokens: 8}, completion.Usage.PromptTokensDetails)
+	assert.Equal(t, &CompletionTokensDetails{}, completion.Usage.CompletionTokensDetails)
+	assert.Equal(t, &ChatCompletionLogprobs{
+		Content: []ChatCompletionTokenLogprob{{
+			Token:       "Hi",
+			Logprob:     -0.5,
+			Bytes:       []int{72, 105},
+			TopLogprobs: []ChatCompletionTopLogprob{{Token: "Hi", Logprob: -0.5, Bytes: []int{72, 105}}},
+		}},
+	}, completion.Choices[0].Logprobs)
+
+	var raw struct {
+		UnknownField string `json:"unknown_field"`
+	}
+
+	require.NoError(t, json.Unmarshal(completion.RawJSON(), &raw))
+	assert.Equal(t, "value", raw.UnknownField)
+}
+
+func TestChatCompletionAccumulatorUsageAndLogprobs(t *testing.T) {
+	var acc ChatCompletionAccumulator
+
+	acc.Accumulate(ChatCompletionChunk{ID: "c1", Model: "gpt-4o", Choices: []ChatCompletionChunkChoice{{
+		Delta:    ChatCompletionDelta{Role: ChatCompletionMessageRoleAssistant, Content: "Hi"},
+		Logprobs: &ChatCompletionLogprobs{Content: []ChatCompletionTokenLogprob{{Token: "Hi"}}},
+	}}})
+	acc.Accumulate(ChatCompletionChunk{ID: "c1", Model: "gpt-4o", Choices: []ChatCompletionChunkChoice{{
+		Delta:        ChatCompletionDelta{Content: "!"},
+		Logprobs:     &ChatCompletionLogprobs{Content: []ChatCompletionTokenLogprob{{Token: "!"}}},
+		FinishReason: "stop",
+	}}})
+	acc.Accumulate(ChatCompletionChunk{ID: "c1", Model: "gpt-4o", Usage: &TokenUsage{TotalTokens: 12}})
+
+	completion := acc.ChatCompletion()
+	assert.Equal(t, "gpt-4o", completion.Model)
+	assert.Equal(t, TokenUsage{TotalTokens: 12}, completion.Usage)
+	require.Len(t, completion.Choices, 1)
+	assert.Equal(t, "Hi!", completion.Choices[0].Message.Content)
+	assert.Equal(t, []ChatCompletionTokenLogprob{{Token: "Hi"}, {Token: "!"}}, completion.Choices[0].Logprobs.Content)
+}
+
+func TestChatCompletionsCreateStreamOutlastsRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerNameContentType, "text/event-stream")
+
+		for i := 0; i < 4; i++ {
+			_, _ = io.WriteString(w, "data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Millisecond * 50)
+		}
+
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	api := New(Config{BaseURL: server.URL, RequestTimeout: time.Millisecond * 100}).ChatCompletions()
+
+	stream, err := api.CreateStream(context.Background(), ChatCompletionParams{Model: "gpt-4o"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var acc ChatCompletionAccumulator
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		acc.Accumulate(chunk)
+	}
+
+	assert.Equal(t, "aaaa", acc.ChatCompletion().Choices[0].Message.Content)
+}
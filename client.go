@@ -2,12 +2,18 @@ package gopenai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -16,6 +22,7 @@ const (
 	headerNameContentType        = "Content-Type"
 	headerNameAuthorization      = "Authorization"
 	headerNameOpenAIOrganization = "OpenAI-Organization"
+	headerNameRequestID          = "X-Request-Id"
 )
 
 type httpClient interface {
@@ -25,6 +32,9 @@ type httpClient interface {
 type client struct {
 	cfg        Config
 	httpClient httpClient
+	// streamHTTPClient sends the requests of streams, whose
+	// bodies are not bounded by the request timeout.
+	streamHTTPClient httpClient
 }
 
 func (c client) Models() ModelsAPI {
@@ -55,62 +65,222 @@ func (c client) Files() FilesAPI {
 	return filesAPI{c: c}
 }
 
+func (c client) Uploads() UploadsAPI {
+	return uploadsAPI{c: c}
+}
+
 func (c client) FineTunes() FineTunesAPI {
 	return fineTunesAPI{c: c}
 }
 
+func (c client) FineTuningJobs() FineTuningJobsAPI {
+	return fineTuningJobsAPI{c: c}
+}
+
 func (c client) Moderations() ModerationsAPI {
 	return moderationsAPI{c: c}
 }
 
-func (c *client) getHTTPResponse(url, method string, data io.Reader, contentType string) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, data)
-	if err != nil {
-		return nil, err
+// streaming returns a copy of the client that sends its
+// requests with the http client of streams, if there is one.
+func (c client) streaming() client {
+	if c.streamHTTPClient != nil {
+		c.httpClient = c.streamHTTPClient
 	}
 
-	if contentType != "" {
-		req.Header.Add(headerNameContentType, contentType)
+	return c
+}
+
+// endpointURL joins the configured base URL, the endpoint path
+// and the given path elements, escaping each element.
+func (c client) endpointURL(endpoint string, elems ...string) string {
+	u := strings.TrimRight(c.cfg.BaseURL, "/") + "/" + strings.Trim(endpoint, "/")
+	for _, elem := range elems {
+		u += "/" + url.PathEscape(elem)
 	}
 
-	req.Header.Add(headerNameAuthorization, fmt.Sprintf("Bearer %s", c.cfg.APIKey))
-	if c.cfg.OrganizationID != "" {
-		req.Header.Add(headerNameOpenAIOrganization, c.cfg.OrganizationID)
+	return u
+}
+
+// bodyFunc returns a fresh reader for a request body. It is called
+// once per attempt so that retried requests can resend their body.
+type bodyFunc func() (io.Reader, error)
+
+func bytesBody(data []byte) bodyFunc {
+	return func() (io.Reader, error) {
+		return bytes.NewReader(data), nil
 	}
+}
 
-	resp, err := c.httpClient.Do(req)
+// getHTTPResponse sends the request, retrying it as configured. The
+// given header, which may be nil, is added to the default headers.
+// Unless RetryPolicy.RetryNonIdempotent is set, requests that are not
+// idempotent are only retried after a timeout or a connection error when
+// none of the request has been written, since the server may have
+// processed a request it did not answer.
+// The body of a retry is opened before waiting for it, so that a body
+// that cannot be sent again fails right away with the error of the
+// last attempt, wrapped along with ErrUploadNotRewindable.
+func (c *client) getHTTPResponse(ctx context.Context, reqURL, method string, header http.Header, body bodyFunc, contentType string) (*http.Response, error) {
+	policy := c.cfg.RetryPolicy
+
+	data, err := openBody(body)
 	if err != nil {
-		if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
-			return nil, ErrRequestTimeout
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		var written atomic.Bool
+
+		trace := &httptrace.ClientTrace{
+			WroteHeaderField: func(string, []string) { written.Store(true) },
 		}
 
-		return nil, err
+		resp, err := c.doHTTPRequest(httptrace.WithClientTrace(ctx, trace), reqURL, method, header, data, contentType)
+
+		retry := policy.shouldRetry(resp, err)
+		if err != nil && written.Load() && !isIdempotent(method) && !policy.RetryNonIdempotent {
+			retry = false
+		}
+
+		if attempt >= policy.MaxAttempts || !retry {
+			return resp, err
+		}
+
+		var respHeader http.Header
+		if resp != nil {
+			respHeader = resp.Header
+		}
+
+		delay := policy.retryDelay(attempt, respHeader)
+		if resp != nil {
+			err = newAPIError(resp)
+			resp.Body.Close()
+		}
+
+		var bodyErr error
+		if data, bodyErr = openBody(body); bodyErr != nil {
+			if errors.Is(bodyErr, ErrUploadNotRewindable) {
+				return nil, &notRewindableError{err: err}
+			}
+
+			return nil, bodyErr
+		}
+
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryEvent{Attempt: attempt, Delay: delay, Err: err})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			closeBody(data)
+
+			return nil, requestError(ctx, ctx.Err())
+		case <-timer.C:
+		}
 	}
+}
 
-	return resp, err
+// isIdempotent reports whether sending a request with the given method
+// more than once has the same effect on the server as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
 }
 
-func (c *client) streamRequestResponse(url, method string, data io.Reader, contentType string, dst io.Writer) error {
-	resp, err := c.getHTTPResponse(url, method, data, contentType)
+// openBody returns the reader of the body for a new attempt,
+// or nil for requests without a body.
+func openBody(body bodyFunc) (io.Reader, error) {
+	if body == nil {
+		return nil, nil
+	}
+
+	return body()
+}
+
+// closeBody closes the reader of a body that is not sent.
+func closeBody(data io.Reader) {
+	if closer, ok := data.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+func (c *client) doHTTPRequest(ctx context.Context, reqURL, method string, header http.Header, data io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, data)
 	if err != nil {
-		return err
+		closeBody(data)
+
+		return nil, err
+	}
+
+	if len(c.cfg.QueryParams) > 0 {
+		query := req.URL.Query()
+		for k, values := range c.cfg.QueryParams {
+			for _, v := range values {
+				query.Add(k, v)
+			}
+		}
+
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for k, values := range c.cfg.Headers {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	if contentType != "" {
+		req.Header.Set(headerNameContentType, contentType)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
+	if c.cfg.APIKey != "" {
+		req.Header.Set(headerNameAuthorization, fmt.Sprintf("Bearer %s", c.cfg.APIKey))
+	}
+
+	if c.cfg.OrganizationID != "" {
+		req.Header.Set(headerNameOpenAIOrganization, c.cfg.OrganizationID)
 	}
 
-	_, err = io.Copy(dst, resp.Body)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		return nil, requestError(ctx, err)
+	}
+
+	return resp, err
+}
+
+// requestError maps errors caused by the request context or by the
+// http client timeout to the package's request errors.
+func requestError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		return ErrRequestCanceled
+	case context.DeadlineExceeded:
+		return ErrRequestDeadlineExceeded
+	}
+
+	if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
+		return ErrRequestTimeout
 	}
 
-	return nil
+	return err
 }
 
-func (c *client) getRequestResponse(url, method string, data io.Reader, contentType string) ([]byte, error) {
-	resp, err := c.getHTTPResponse(url, method, data, contentType)
+func (c *client) getRequestResponse(ctx context.Context, reqURL, method string, body bodyFunc, contentType string) ([]byte, error) {
+	resp, err := c.getHTTPResponse(ctx, reqURL, method, nil, body, contentType)
 	if err != nil {
 		return nil, err
 	}
@@ -118,38 +288,27 @@ func (c *client) getRequestResponse(url, method string, data io.Reader, contentT
 
 	// check if response is an error
 	if resp.StatusCode >= 400 {
-		var errResp struct {
-			Error struct {
-				Message string      `json:"message"`
-				Type    string      `json:"type"`
-				Param   interface{} `json:"param"`
-				Code    string      `json:"code"`
-			} `json:"error"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-			return nil, err
-		}
-
-		errMsg := fmt.Sprintf(errMsgTpl, errResp.Error.Message,
-			errResp.Error.Type, errResp.Error.Code, errResp.Error.Param)
+		return nil, newAPIError(resp)
+	}
 
-		return nil, errors.New(errMsg)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(ctx, err)
 	}
 
-	return io.ReadAll(resp.Body)
+	return data, nil
 }
 
-func (c client) getJSONRequestResponse(url, method string, data interface{}) ([]byte, error) {
-	var reqBody io.Reader
+func (c client) getJSONRequestResponse(ctx context.Context, reqURL, method string, data interface{}) ([]byte, error) {
+	var reqBody bodyFunc
 	if data != nil {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
 
-		reqBody = bytes.NewReader(jsonData)
+		reqBody = bytesBody(jsonData)
 	}
 
-	return c.getRequestResponse(url, method, reqBody, contentTypeJSON)
+	return c.getRequestResponse(ctx, reqURL, method, reqBody, contentTypeJSON)
 }
@@ -0,0 +1,166 @@
+package gopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRequestResponseContextErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c := client{httpClient: &http.Client{Timeout: time.Second * 5}}
+
+	testCases := []struct {
+		name        string
+		ctx         func() (context.Context, context.CancelFunc)
+		expectedErr error
+	}{
+		{
+			name: "canceled context",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx, cancel
+			},
+			expectedErr: ErrRequestCanceled,
+		},
+		{
+			name: "deadline exceeded",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Millisecond*10)
+			},
+			expectedErr: ErrRequestDeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := tc.ctx()
+			defer cancel()
+
+			_, err := c.getRequestResponse(ctx, server.URL, http.MethodGet, nil, "")
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestEndpointURL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		baseURL     string
+		endpoint    string
+		elems       []string
+		expectedURL string
+	}{
+		{
+			name:        "no trailing slash",
+			baseURL:     "http://localhost:8080/v1",
+			endpoint:    filesAPIEndpoint,
+			expectedURL: "http://localhost:8080/v1/files",
+		},
+		{
+			name:        "trailing slash",
+			baseURL:     "http://localhost:8080/v1/",
+			endpoint:    filesAPIEndpoint,
+			elems:       []string{"file-123", "content"},
+			expectedURL: "http://localhost:8080/v1/files/file-123/content",
+		},
+		{
+			name:        "escaped elements",
+			baseURL:     "http://localhost:8080",
+			endpoint:    modelsAPIEndpoint,
+			elems:       []string{"ft:gpt-3.5-turbo:org/name"},
+			expectedURL: "http://localhost:8080/models/ft:gpt-3.5-turbo:org%2Fname",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := client{cfg: Config{BaseURL: tc.baseURL}}
+			assert.Equal(t, tc.expectedURL, c.endpointURL(tc.endpoint, tc.elems...))
+		})
+	}
+}
+
+func TestClientBaseURLHeadersAndQueryParams(t *testing.T) {
+	var received *http.Request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		_, _ = w.Write([]byte(`{"id": "my-model"}`))
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		APIKey:      "key",
+		BaseURL:     server.URL + "/v1/",
+		Headers:     http.Header{"X-Custom": []string{"value"}},
+		QueryParams: url.Values{"api-version": []string{"2023-05-15"}},
+	})
+
+	model, err := c.Models().GetByID("my-model")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-model", model.ID)
+	assert.Equal(t, "/v1/models/my-model", received.URL.Path)
+	assert.Equal(t, "2023-05-15", received.URL.Query().Get("api-version"))
+	assert.Equal(t, "value", received.Header.Get("X-Custom"))
+	assert.Equal(t, "Bearer key", received.Header.Get(headerNameAuthorization))
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	testCases := []struct {
+		name        string
+		handler     http.HandlerFunc
+		expectedErr error
+	}{
+		{
+			name: "slow headers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Millisecond * 200)
+				_, _ = w.Write([]byte(`{"id": "my-model"}`))
+			},
+			expectedErr: ErrRequestTimeout,
+		},
+		{
+			name: "slow body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"id": `))
+				w.(http.Flusher).Flush()
+				time.Sleep(time.Millisecond * 200)
+				_, _ = w.Write([]byte(`"my-model"}`))
+			},
+			expectedErr: ErrRequestTimeout,
+		},
+		{
+			name: "fast response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"id": "my-model"}`))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			_, err := New(Config{BaseURL: server.URL, RequestTimeout: time.Millisecond * 50}).Models().GetByID("my-model")
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -8,4 +8,86 @@ type TokenUsage struct {
 	CompletionTokens int `json:"completion_tokens"`
 	// TotalTokens is the sum of prompt tokens and completion tokens.
 	TotalTokens int `json:"total_tokens"`
+	// PromptTokensDetails is the breakdown of the prompt tokens.
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+	// CompletionTokensDetails is the breakdown of the completion tokens.
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails represents the breakdown of the prompt tokens.
+type PromptTokensDetails struct {
+	// CachedTokens is the number of prompt tokens read from the cache.
+	CachedTokens int `json:"cached_tokens"`
+	// AudioTokens is the number of audio input tokens.
+	AudioTokens int `json:"audio_tokens"`
+}
+
+// CompletionTokensDetails represents the breakdown of the completion tokens.
+type CompletionTokensDetails struct {
+	// ReasoningTokens is the number of tokens generated
+	// by reasoning models for their reasoning.
+	ReasoningTokens int `json:"reasoning_tokens"`
+	// AudioTokens is the number of audio output tokens.
+	AudioTokens int `json:"audio_tokens"`
+	// AcceptedPredictionTokens is the number of tokens of
+	// the predicted output that appeared in the completion.
+	AcceptedPredictionTokens int `json:"accepted_prediction_tokens"`
+	// RejectedPredictionTokens is the number of tokens of
+	// the predicted output that did not appear in the completion.
+	RejectedPredictionTokens int `json:"rejected_prediction_tokens"`
+}
+
+func (u TokenUsage) add(other TokenUsage) TokenUsage {
+	sum := TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+	}
+
+	if u.PromptTokensDetails != nil || other.PromptTokensDetails != nil {
+		a, b := derefOrZero(u.PromptTokensDetails), derefOrZero(other.PromptTokensDetails)
+		sum.PromptTokensDetails = &PromptTokensDetails{
+			CachedTokens: a.CachedTokens + b.CachedTokens,
+			AudioTokens:  a.AudioTokens + b.AudioTokens,
+		}
+	}
+
+	if u.CompletionTokensDetails != nil || other.CompletionTokensDetails != nil {
+		a, b := derefOrZero(u.CompletionTokensDetails), derefOrZero(other.CompletionTokensDetails)
+		sum.CompletionTokensDetails = &CompletionTokensDetails{
+			ReasoningTokens:          a.ReasoningTokens + b.ReasoningTokens,
+			AudioTokens:              a.AudioTokens + b.AudioTokens,
+			AcceptedPredictionTokens: a.AcceptedPredictionTokens + b.AcceptedPredictionTokens,
+			RejectedPredictionTokens: a.RejectedPredictionTokens + b.RejectedPredictionTokens,
+		}
+	}
+
+	return sum
+}
+
+func derefOrZero[T any](v *T) T {
+	if v == nil {
+		var zero T
+
+		return zero
+	}
+
+	return *v
+}
+
+// Float64 returns a pointer to the given value. It is meant for optional
+// request parameters, which are omitted when nil and sent as is otherwise,
+// so that zero values such as a temperature of 0 can be sent explicitly.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// Int returns a pointer to the given value, for optional request parameters.
+func Int(v int) *int {
+	return &v
+}
+
+// Bool returns a pointer to the given value, for optional request parameters.
+func Bool(v bool) *bool {
+	return &v
 }
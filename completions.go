@@ -1,9 +1,10 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"sort"
 )
 
 const completionsAPIEndpoint = "/completions"
@@ -39,6 +40,8 @@ type CompletionChoice struct {
 type Completion struct {
 	// ID is the ID of the completion.
 	ID string `json:"id"`
+	// Object is the object type, which is always "text_completion".
+	Object string `json:"object"`
 	// Created is the UNIX timestamp of when the completion was created.
 	Created int `json:"created"`
 	// Model is the ID of the model used to generate the completion.
@@ -64,16 +67,17 @@ type CompletionParams struct {
 	MaxTokens int `json:"max_tokens,omitempty"`
 	// Temperature is a value controlling the randomness of the
 	// generated tokens, with higher values leading to more random completions.
-	Temperature float64 `json:"temperature,omitempty"`
+	// Like the other optional parameters, it is omitted when nil.
+	Temperature *float64 `json:"temperature,omitempty"`
 	// TopP is a value between 0 and 1 controlling the amount of diversity in
 	// the generated completions, with higher values leading
 	// to less diverse completions.
-	TopP float64 `json:"top_p,omitempty"`
+	TopP *float64 `json:"top_p,omitempty"`
 	// N is the number of completions to generate for the prompt.
 	N int `json:"n,omitempty"`
-	// Logprobs specifies if the log probabilities for each token in
-	// the completion should be returned.
-	Logprobs int `json:"logprobs,omitempty"`
+	// Logprobs is the number of most likely tokens whose log probabilities
+	// are returned for each position, with 0 returning only the sampled one.
+	Logprobs *int `json:"logprobs,omitempty"`
 	// Echo specifies if the prompt should be repeated in the returned text.
 	Echo bool `json:"echo,omitempty"`
 	// Stop is a string that, if encountered in the generated text,
@@ -81,15 +85,98 @@ type CompletionParams struct {
 	Stop string `json:"stop,omitempty"`
 	// PresencePenalty is the penalty applied to log probabilities of
 	// tokens that are not present in the prompt.
-	PresencePenalty float64 `json:"presence_penalty,omitempty"`
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
 	// FrequencyPenalty is the penalty applied to log probabilities based
 	// on the token's frequency in the training data.
-	FrequencyPenalty float64 `json:"frequency_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
 	// BestOf specifies the number of best completions to keep, out
 	// of all the generated completions.
 	BestOf int `json:"best_of,omitempty"`
 	// User is a string that can be used to specify a user id.
 	User string `json:"user,omitempty"`
+	// Stream specifies if the completion should be streamed as
+	// it is being generated. It is set by CreateStream.
+	Stream bool `json:"stream,omitempty"`
+}
+
+// CompletionStream is a stream of completion chunks. Each chunk is a
+// Completion whose choices hold the text, and the log-probabilities if
+// requested, generated since the previous chunk.
+// It must be closed when no longer needed.
+type CompletionStream struct {
+	r *streamReader[Completion]
+}
+
+// Recv returns the next chunk in the stream. It returns io.EOF once
+// the stream has ended and an *APIError if the API sends an error
+// event in the middle of the stream.
+func (s *CompletionStream) Recv() (Completion, error) {
+	return s.r.recv()
+}
+
+// Close closes the underlying connection of the stream.
+func (s *CompletionStream) Close() error {
+	return s.r.close()
+}
+
+// CompletionAccumulator rebuilds a Completion out of the
+// chunks of a stream. The zero value is ready to use.
+type CompletionAccumulator struct {
+	completion Completion
+	choices    map[int]*CompletionChoice
+}
+
+// Accumulate adds the given chunk to the completion being rebuilt.
+func (a *CompletionAccumulator) Accumulate(chunk Completion) {
+	if a.choices == nil {
+		a.choices = map[int]*CompletionChoice{}
+	}
+
+	a.completion.ID = chunk.ID
+	a.completion.Object = chunk.Object
+	a.completion.Created = chunk.Created
+	a.completion.Model = chunk.Model
+
+	if chunk.Usage != (TokenUsage{}) {
+		a.completion.Usage = chunk.Usage
+	}
+
+	for _, chunkChoice := range chunk.Choices {
+		choice, ok := a.choices[chunkChoice.Index]
+		if !ok {
+			choice = &CompletionChoice{Index: chunkChoice.Index}
+			a.choices[chunkChoice.Index] = choice
+		}
+
+		choice.Text += chunkChoice.Text
+
+		logprobs := &choice.Logprobs
+		logprobs.TextOffset = append(logprobs.TextOffset, chunkChoice.Logprobs.TextOffset...)
+		logprobs.TokenLogprobs = append(logprobs.TokenLogprobs, chunkChoice.Logprobs.TokenLogprobs...)
+		logprobs.Tokens = append(logprobs.Tokens, chunkChoice.Logprobs.Tokens...)
+		logprobs.TopLogprobs = append(logprobs.TopLogprobs, chunkChoice.Logprobs.TopLogprobs...)
+
+		if chunkChoice.FinishReason != "" {
+			choice.FinishReason = chunkChoice.FinishReason
+		}
+	}
+}
+
+// Completion returns the completion rebuilt from the chunks
+// accumulated so far, with its choices sorted by index.
+func (a *CompletionAccumulator) Completion() Completion {
+	completion := a.completion
+	completion.Choices = make([]CompletionChoice, 0, len(a.choices))
+
+	for _, choice := range a.choices {
+		completion.Choices = append(completion.Choices, *choice)
+	}
+
+	sort.Slice(completion.Choices, func(i, j int) bool {
+		return completion.Choices[i].Index < completion.Choices[j].Index
+	})
+
+	return completion
 }
 
 // CompletionsAPI is an interface for creating completions.
@@ -97,6 +184,12 @@ type CompletionsAPI interface {
 	// Create generates a new completion based on the given
 	// CompletionParams and returns it as a Completion object.
 	Create(CompletionParams) (Completion, error)
+	// CreateWithContext is like Create but uses the given
+	// context for the request.
+	CreateWithContext(context.Context, CompletionParams) (Completion, error)
+	// CreateStream generates a new completion based on the given
+	// CompletionParams and streams it as it is being generated.
+	CreateStream(context.Context, CompletionParams) (*CompletionStream, error)
 }
 
 type completionsAPI struct {
@@ -104,8 +197,12 @@ type completionsAPI struct {
 }
 
 func (api completionsAPI) Create(params CompletionParams) (Completion, error) {
-	url := fmt.Sprintf("%s%s", baseURL, completionsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api completionsAPI) CreateWithContext(ctx context.Context, params CompletionParams) (Completion, error) {
+	url := api.c.endpointURL(completionsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return Completion{}, err
 	}
@@ -117,3 +214,15 @@ func (api completionsAPI) Create(params CompletionParams) (Completion, error) {
 
 	return response, nil
 }
+
+func (api completionsAPI) CreateStream(ctx context.Context, params CompletionParams) (*CompletionStream, error) {
+	params.Stream = true
+
+	url := api.c.endpointURL(completionsAPIEndpoint)
+	r, err := getStreamResponse[Completion](ctx, api.c, url, http.MethodPost, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompletionStream{r: r}, nil
+}
@@ -0,0 +1,85 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionsCreateStream(t *testing.T) {
+	server := newTestStreamServer(t, ""+
+		"data: {\"id\":\"cmpl-1\",\"object\":\"text_completion\",\"created\":1,\"model\":\"davinci\",\"choices\":["+
+		"{\"text\":\"Hel\",\"index\":0,\"logprobs\":{\"tokens\":[\"Hel\"],\"token_logprobs\":[-0.1],"+
+		"\"top_logprobs\":[{\"Hel\":-0.1}],\"text_offset\":[0]},\"finish_reason\":null}]}\n\n"+
+		"data: {\"id\":\"cmpl-1\",\"object\":\"text_completion\",\"created\":1,\"model\":\"davinci\",\"choices\":["+
+		"{\"text\":\"Hi\",\"index\":1,\"logprobs\":{\"tokens\":[\"Hi\"],\"token_logprobs\":[-0.3],"+
+		"\"top_logprobs\":[{\"Hi\":-0.3}],\"text_offset\":[0]},\"finish_reason\":\"stop\"}]}\n\n"+
+		"data: {\"id\":\"cmpl-1\",\"object\":\"text_completion\",\"created\":1,\"model\":\"davinci\",\"choices\":["+
+		"{\"text\":\"lo\",\"index\":0,\"logprobs\":{\"tokens\":[\"lo\"],\"token_logprobs\":[-0.2],"+
+		"\"top_logprobs\":[{\"lo\":-0.2}],\"text_offset\":[3]},\"finish_reason\":\"length\"}]}\n\n"+
+		"data: [DONE]\n\n")
+
+	api := New(Config{BaseURL: server.URL}).Completions()
+
+	stream, err := api.CreateStream(context.Background(), CompletionParams{Model: "davinci", Logprobs: Int(1)})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var acc CompletionAccumulator
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		acc.Accumulate(chunk)
+	}
+
+	expected := Completion{
+		ID:      "cmpl-1",
+		Object:  "text_completion",
+		Created: 1,
+		Model:   "davinci",
+		Choices: []CompletionChoice{
+			{
+				Text:  "Hello",
+				Index: 0,
+				Logprobs: LogProbabilities{
+					TextOffset:    []int{0, 3},
+					TokenLogprobs: []float64{-0.1, -0.2},
+					Tokens:        []string{"Hel", "lo"},
+					TopLogprobs:   []map[string]float64{{"Hel": -0.1}, {"lo": -0.2}},
+				},
+				FinishReason: "length",
+			},
+			{
+				Text:  "Hi",
+				Index: 1,
+				Logprobs: LogProbabilities{
+					TextOffset:    []int{0},
+					TokenLogprobs: []float64{-0.3},
+					Tokens:        []string{"Hi"},
+					TopLogprobs:   []map[string]float64{{"Hi": -0.3}},
+				},
+				FinishReason: "stop",
+			},
+		},
+	}
+
+	assert.Equal(t, expected, acc.Completion())
+}
+
+func TestCompletionParamsJSONExplicitZero(t *testing.T) {
+	data, err := json.Marshal(CompletionParams{Model: "davinci", Temperature: Float64(0), Logprobs: Int(0)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model": "davinci", "temperature": 0, "logprobs": 0}`, string(data))
+
+	data, err = json.Marshal(EditParams{Model: "text-davinci-edit-001", Instruction: "Fix", TopP: Float64(0)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model": "text-davinci-edit-001", "instruction": "Fix", "top_p": 0}`, string(data))
+}
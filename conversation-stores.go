@@ -0,0 +1,198 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	conversationFileExt  = ".json"
+	conversationFilePerm = 0o600
+	conversationDirPerm  = 0o700
+)
+
+var (
+	// ErrConversationNotFound is an error that indicates there
+	// is no conversation with the given ID in a store.
+	ErrConversationNotFound = errors.New("conversation not found")
+	// ErrEmptyConversationID is an error that indicates
+	// a conversation without an ID was saved.
+	ErrEmptyConversationID = errors.New("empty conversation ID")
+)
+
+// ConversationStore persists conversations by ID.
+// Implementations must be safe for concurrent use.
+type ConversationStore interface {
+	// Save saves the conversation, replacing any
+	// conversation previously saved with the same ID.
+	Save(ctx context.Context, conversation *Conversation) error
+	// Load returns the conversation with the given ID. It returns
+	// ErrConversationNotFound if there is no such conversation.
+	Load(ctx context.Context, id string) (*Conversation, error)
+	// Delete deletes the conversation with the given ID. It returns
+	// ErrConversationNotFound if there is no such conversation.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryConversationStore is a ConversationStore keeping the
+// conversations in memory. The zero value is ready to use.
+type MemoryConversationStore struct {
+	mu            sync.RWMutex
+	conversations map[string][]byte
+}
+
+// Save implements the ConversationStore interface.
+func (s *MemoryConversationStore) Save(_ context.Context, conversation *Conversation) error {
+	id := conversation.ID()
+	if id == "" {
+		return ErrEmptyConversationID
+	}
+
+	// conversations are stored as JSON so that the stored
+	// copy is not affected by later changes to conversation
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conversations == nil {
+		s.conversations = map[string][]byte{}
+	}
+
+	s.conversations[id] = data
+
+	return nil
+}
+
+// Load implements the ConversationStore interface.
+func (s *MemoryConversationStore) Load(_ context.Context, id string) (*Conversation, error) {
+	s.mu.RLock()
+	data, ok := s.conversations[id]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, id)
+	}
+
+	conversation := &Conversation{}
+	if err := json.Unmarshal(data, conversation); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// Delete implements the ConversationStore interface.
+func (s *MemoryConversationStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.conversations[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, id)
+	}
+
+	delete(s.conversations, id)
+
+	return nil
+}
+
+// FileConversationStore is a ConversationStore keeping each
+// conversation in a JSON file of a directory.
+type FileConversationStore struct {
+	dir string
+}
+
+// NewFileConversationStore returns a store keeping the conversations
+// in the given directory, which is created if it does not exist.
+func NewFileConversationStore(dir string) (*FileConversationStore, error) {
+	if err := os.MkdirAll(dir, conversationDirPerm); err != nil {
+		return nil, err
+	}
+
+	return &FileConversationStore{dir: dir}, nil
+}
+
+// Save implements the ConversationStore interface. The file is
+// replaced atomically so that concurrent loads never see a partial one.
+func (s *FileConversationStore) Save(_ context.Context, conversation *Conversation) error {
+	id := conversation.ID()
+	if id == "" {
+		return ErrEmptyConversationID
+	}
+
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, ".conversation-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	if err := file.Chmod(conversationFilePerm); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path(id))
+}
+
+// Load implements the ConversationStore interface.
+func (s *FileConversationStore) Load(_ context.Context, id string) (*Conversation, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, id)
+		}
+
+		return nil, err
+	}
+
+	conversation := &Conversation{}
+	if err := json.Unmarshal(data, conversation); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// Delete implements the ConversationStore interface.
+func (s *FileConversationStore) Delete(_ context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrConversationNotFound, id)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// path returns the path of the file of the conversation with the
+// given ID, which is escaped so that it cannot escape the directory.
+func (s *FileConversationStore) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+conversationFileExt)
+}
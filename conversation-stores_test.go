@@ -0,0 +1,53 @@
+package gopenai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationStores(t *testing.T) {
+	fileStore, err := NewFileConversationStore(t.TempDir())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		store ConversationStore
+	}{
+		{name: "memory", store: &MemoryConversationStore{}},
+		{name: "file", store: fileStore},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			conversation := NewConversation("user/1", "Be brief")
+			conversation.Append(newTestUserMessage("Hi"), newTestAssistantMessage("Hello!"))
+			require.NoError(t, tc.store.Save(ctx, conversation))
+
+			// later changes are not saved
+			conversation.Append(newTestUserMessage("Bye"))
+
+			loaded, err := tc.store.Load(ctx, "user/1")
+			require.NoError(t, err)
+			assert.Equal(t, "user/1", loaded.ID())
+			assert.Equal(t, "Be brief", loaded.SystemPrompt())
+			assert.Len(t, loaded.Messages(), 3)
+
+			require.NoError(t, tc.store.Save(ctx, conversation))
+			loaded, err = tc.store.Load(ctx, "user/1")
+			require.NoError(t, err)
+			assert.Equal(t, conversation.Messages(), loaded.Messages())
+
+			require.NoError(t, tc.store.Delete(ctx, "user/1"))
+
+			_, err = tc.store.Load(ctx, "user/1")
+			assert.ErrorIs(t, err, ErrConversationNotFound)
+			assert.ErrorIs(t, tc.store.Delete(ctx, "user/1"), ErrConversationNotFound)
+			assert.ErrorIs(t, tc.store.Save(ctx, NewConversation("", "")), ErrEmptyConversationID)
+		})
+	}
+}
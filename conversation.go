@@ -0,0 +1,233 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Conversation is a chat session holding a system prompt and the
+// history of messages, grouped in turns. It is safe for concurrent use.
+type Conversation struct {
+	// sendMu serializes the turns sent to the model, appended and
+	// undone, so that each reply is generated from the full history.
+	sendMu sync.Mutex
+	mu     sync.RWMutex
+
+	id           string
+	systemPrompt string
+	messages     []ChatCompletionMessage
+	// turns holds the index of the first message of each turn
+	turns []int
+}
+
+// conversationJSON is the JSON representation of a Conversation.
+type conversationJSON struct {
+	ID           string                  `json:"id"`
+	SystemPrompt string                  `json:"system_prompt,omitempty"`
+	Messages     []ChatCompletionMessage `json:"messages"`
+	Turns        []int                   `json:"turns"`
+}
+
+// NewConversation returns an empty conversation with the given
+// ID, which identifies it in a ConversationStore, and system prompt.
+func NewConversation(id, systemPrompt string) *Conversation {
+	return &Conversation{
+		id:           id,
+		systemPrompt: systemPrompt,
+	}
+}
+
+// ID returns the ID of the conversation.
+func (c *Conversation) ID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.id
+}
+
+// SystemPrompt returns the system prompt of the conversation.
+func (c *Conversation) SystemPrompt() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.systemPrompt
+}
+
+// SetSystemPrompt replaces the system prompt of the conversation.
+func (c *Conversation) SetSystemPrompt(systemPrompt string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.systemPrompt = systemPrompt
+}
+
+// Messages returns a copy of the messages of the conversation,
+// starting with the system prompt if there is one.
+func (c *Conversation) Messages() []ChatCompletionMessage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	messages := make([]ChatCompletionMessage, 0, len(c.messages)+1)
+	if c.systemPrompt != "" {
+		messages = append(messages, ChatCompletionMessage{
+			Role:    ChatCompletionMessageRoleSystem,
+			Content: c.systemPrompt,
+		})
+	}
+
+	return append(messages, c.messages...)
+}
+
+// Turns returns the number of turns of the conversation.
+func (c *Conversation) Turns() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.turns)
+}
+
+// Append appends the given messages to the conversation as a new turn.
+// A Send in progress is waited for, and the messages follow its turn.
+func (c *Conversation) Append(messages ...ChatCompletionMessage) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.append(messages...)
+}
+
+// append appends the given messages as a new turn. The caller must hold sendMu.
+func (c *Conversation) append(messages ...ChatCompletionMessage) {
+	if len(messages) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.turns = append(c.turns, len(c.messages))
+	c.messages = append(c.messages, messages...)
+}
+
+// Send sends the given message to the model along with the history
+// of the conversation and, if the completion succeeds, appends the
+// message and the reply as a new turn. The messages of params are
+// replaced by the ones of the conversation. Concurrent calls are
+// serialized so that each reply is generated from the full history.
+func (c *Conversation) Send(ctx context.Context, api ChatCompletionsAPI, params ChatCompletionParams, message ChatCompletionMessage) (ChatCompletion, error) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	params.Messages = append(c.Messages(), message)
+
+	completion, err := api.CreateWithContext(ctx, params)
+	if err != nil {
+		return completion, err
+	}
+
+	if len(completion.Choices) == 0 {
+		return completion, ErrNoChoices
+	}
+
+	c.append(message, completion.Choices[0].Message)
+
+	return completion, nil
+}
+
+// SendText sends a user message holding the given text. See Send.
+func (c *Conversation) SendText(ctx context.Context, api ChatCompletionsAPI, params ChatCompletionParams, text string) (ChatCompletion, error) {
+	return c.Send(ctx, api, params, ChatCompletionMessage{
+		Role:    ChatCompletionMessageRoleUser,
+		Content: text,
+	})
+}
+
+// Undo removes the last turn of the conversation and returns
+// its messages. It returns nil if the conversation is empty. A
+// Send in progress is waited for, and its turn is the one removed.
+func (c *Conversation) Undo() []ChatCompletionMessage {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.turns) == 0 {
+		return nil
+	}
+
+	start := c.turns[len(c.turns)-1]
+	undone := append([]ChatCompletionMessage(nil), c.messages[start:]...)
+
+	c.turns = c.turns[:len(c.turns)-1]
+	c.messages = c.messages[:start]
+
+	return undone
+}
+
+// Fork returns a copy of the conversation with the given ID, which
+// continues independently from the conversation from now on.
+func (c *Conversation) Fork(id string) *Conversation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &Conversation{
+		id:           id,
+		systemPrompt: c.systemPrompt,
+		messages:     append([]ChatCompletionMessage(nil), c.messages...),
+		turns:        append([]int(nil), c.turns...),
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c *Conversation) MarshalJSON() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	messages := c.messages
+	if messages == nil {
+		messages = []ChatCompletionMessage{}
+	}
+
+	turns := c.turns
+	if turns == nil {
+		turns = []int{}
+	}
+
+	return json.Marshal(conversationJSON{
+		ID:           c.id,
+		SystemPrompt: c.systemPrompt,
+		Messages:     messages,
+		Turns:        turns,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Conversation) UnmarshalJSON(data []byte) error {
+	var decoded conversationJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	// conversations saved without turns hold a single turn
+	if len(decoded.Turns) == 0 && len(decoded.Messages) > 0 {
+		decoded.Turns = []int{0}
+	}
+
+	for i, start := range decoded.Turns {
+		if start >= len(decoded.Messages) || (i == 0 && start != 0) || (i > 0 && start <= decoded.Turns[i-1]) {
+			return fmt.Errorf("invalid start %d of conversation turn %d", start, i)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.id = decoded.ID
+	c.systemPrompt = decoded.SystemPrompt
+	c.messages = decoded.Messages
+	c.turns = decoded.Turns
+
+	return nil
+}
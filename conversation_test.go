@@ -0,0 +1,194 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserMessage(content string) ChatCompletionMessage {
+	return ChatCompletionMessage{Role: ChatCompletionMessageRoleUser, Content: content}
+}
+
+func newTestAssistantMessage(content string) ChatCompletionMessage {
+	return ChatCompletionMessage{Role: ChatCompletionMessageRoleAssistant, Content: content}
+}
+
+func TestConversationSend(t *testing.T) {
+	api := &fakeChatCompletionsAPI{responses: []ChatCompletion{
+		newTestChatCompletion(ChatCompletionMessage{Content: "Hello!"}, "stop"),
+		newTestChatCompletion(ChatCompletionMessage{Content: "Fine."}, "stop"),
+	}}
+
+	conversation := NewConversation("c1", "Be brief")
+	params := ChatCompletionParams{Model: "gpt-4o"}
+
+	_, err := conversation.SendText(context.Background(), api, params, "Hi")
+	require.NoError(t, err)
+
+	completion, err := conversation.SendText(context.Background(), api, params, "How are you?")
+	require.NoError(t, err)
+	assert.Equal(t, "Fine.", completion.Choices[0].Message.Content)
+
+	assert.Equal(t, []ChatCompletionMessage{
+		{Role: ChatCompletionMessageRoleSystem, Content: "Be brief"},
+		newTestUserMessage("Hi"),
+		newTestAssistantMessage("Hello!"),
+		newTestUserMessage("How are you?"),
+	}, api.requests[1].Messages)
+	assert.Equal(t, "gpt-4o", api.requests[1].Model)
+
+	assert.Equal(t, 2, conversation.Turns())
+	assert.Len(t, conversation.Messages(), 5)
+}
+
+func TestConversationUndoAndFork(t *testing.T) {
+	conversation := NewConversation("c1", "")
+	conversation.Append(newTestUserMessage("Hi"), newTestAssistantMessage("Hello!"))
+	conversation.Append(newTestUserMessage("Bye"), newTestAssistantMessage("Bye!"))
+
+	fork := conversation.Fork("c2")
+	assert.Equal(t, "c2", fork.ID())
+
+	assert.Equal(t, []ChatCompletionMessage{newTestUserMessage("Bye"), newTestAssistantMessage("Bye!")}, conversation.Undo())
+	assert.Equal(t, []ChatCompletionMessage{newTestUserMessage("Hi"), newTestAssistantMessage("Hello!")}, conversation.Messages())
+	assert.Len(t, fork.Messages(), 4)
+
+	conversation.Undo()
+	assert.Empty(t, conversation.Messages())
+	assert.Nil(t, conversation.Undo())
+}
+
+func TestConversationJSON(t *testing.T) {
+	conversation := NewConversation("c1", "Be brief")
+	conversation.Append(newTestUserMessage("Hi"), newTestAssistantMessage("Hello!"))
+	conversation.Append(newTestUserMessage("Bye"))
+
+	data, err := json.Marshal(conversation)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "c1",
+		"system_prompt": "Be brief",
+		"messages": [
+			{"role": "user", "content": "Hi"},
+			{"role": "assistant", "content": "Hello!"},
+			{"role": "user", "content": "Bye"}
+		],
+		"turns": [0, 2]
+	}`, string(data))
+
+	decoded := &Conversation{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, conversation.Messages(), decoded.Messages())
+	assert.Equal(t, 2, decoded.Turns())
+
+	err = json.Unmarshal([]byte(`{"id": "c1", "messages": [{"role": "user", "content": "Hi"}], "turns": [1]}`), decoded)
+	assert.Error(t, err)
+}
+
+func TestConversationConcurrentSend(t *testing.T) {
+	const sends = 10
+
+	api := &fakeChatCompletionsAPI{}
+	for i := 0; i < sends; i++ {
+		api.responses = append(api.responses, newTestChatCompletion(ChatCompletionMessage{Content: "ok"}, "stop"))
+	}
+
+	conversation := NewConversation("c1", "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := conversation.SendText(context.Background(), api, ChatCompletionParams{}, fmt.Sprint(i))
+			assert.NoError(t, err)
+			conversation.Messages()
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, sends, conversation.Turns())
+
+	for i, request := range api.requests {
+		assert.Len(t, request.Messages, 2*i+1)
+	}
+}
+
+func TestConversationUndoWaitsForSend(t *testing.T) {
+	api := &fakeChatCompletionsAPI{
+		responses: []ChatCompletion{newTestChatCompletion(ChatCompletionMessage{Content: "Bye!"}, "stop")},
+		started:   make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+
+	conversation := NewConversation("c1", "")
+	conversation.Append(newTestUserMessage("Hi"), newTestAssistantMessage("Hello!"))
+
+	sent := make(chan error)
+	go func() {
+		_, err := conversation.SendText(context.Background(), api, ChatCompletionParams{}, "Bye")
+		sent <- err
+	}()
+
+	<-api.started
+
+	undone := make(chan []ChatCompletionMessage)
+	go func() { undone <- conversation.Undo() }()
+
+	select {
+	case <-undone:
+		t.Fatal("undo did not wait for the send in progress")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(api.release)
+	require.NoError(t, <-sent)
+
+	assert.Equal(t, []ChatCompletionMessage{newTestUserMessage("Bye"), newTestAssistantMessage("Bye!")}, <-undone)
+	assert.Equal(t, []ChatCompletionMessage{newTestUserMessage("Hi"), newTestAssistantMessage("Hello!")}, conversation.Messages())
+}
+
+func TestConversationAppendWaitsForSend(t *testing.T) {
+	api := &fakeChatCompletionsAPI{
+		responses: []ChatCompletion{newTestChatCompletion(ChatCompletionMessage{Content: "Hello!"}, "stop")},
+		started:   make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+
+	conversation := NewConversation("c1", "")
+
+	sent := make(chan error)
+	go func() {
+		_, err := conversation.SendText(context.Background(), api, ChatCompletionParams{}, "Hi")
+		sent <- err
+	}()
+
+	<-api.started
+
+	appended := make(chan struct{})
+	go func() {
+		conversation.Append(newTestUserMessage("Bye"))
+		close(appended)
+	}()
+
+	close(api.release)
+	require.NoError(t, <-sent)
+	<-appended
+
+	assert.Equal(t, []ChatCompletionMessage{
+		newTestUserMessage("Hi"),
+		newTestAssistantMessage("Hello!"),
+		newTestUserMessage("Bye"),
+	}, conversation.Messages())
+	assert.Equal(t, 2, conversation.Turns())
+}
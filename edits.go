@@ -1,8 +1,8 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -41,9 +41,9 @@ type EditParams struct {
 	// N is the number of corrections to return.
 	N int `json:"n,omitempty"`
 	// Temperature is a value used to control the randomness of the response.
-	Temperature float64 `json:"temperature,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
 	// TopP is a value used to control the diversity of the response.
-	TopP float64 `json:"top_p,omitempty"`
+	TopP *float64 `json:"top_p,omitempty"`
 }
 
 // EditsAPI is an interface that provides methods for text
@@ -52,6 +52,9 @@ type EditsAPI interface {
 	// Create makes a text correction request to OpenAI's GPT-3 API
 	// using the provided parameters.
 	Create(EditParams) (Edit, error)
+	// CreateWithContext is like Create but uses the given
+	// context for the request.
+	CreateWithContext(context.Context, EditParams) (Edit, error)
 }
 
 type editsAPI struct {
@@ -59,8 +62,12 @@ type editsAPI struct {
 }
 
 func (api editsAPI) Create(params EditParams) (Edit, error) {
-	url := fmt.Sprintf("%s%s", baseURL, editsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api editsAPI) CreateWithContext(ctx context.Context, params EditParams) (Edit, error) {
+	url := api.c.endpointURL(editsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return Edit{}, err
 	}
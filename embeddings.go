@@ -1,8 +1,8 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -32,6 +32,9 @@ type EmbeddingParams struct {
 type EmbeddingsAPI interface {
 	// Create generates an embedding for the given parameters
 	Create(EmbeddingParams) (Embedding, error)
+	// CreateWithContext is like Create but uses the given
+	// context for the request
+	CreateWithContext(context.Context, EmbeddingParams) (Embedding, error)
 }
 
 type embeddingsAPI struct {
@@ -39,8 +42,12 @@ type embeddingsAPI struct {
 }
 
 func (api embeddingsAPI) Create(params EmbeddingParams) (Embedding, error) {
-	url := fmt.Sprintf("%s%s", baseURL, embeddingsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api embeddingsAPI) CreateWithContext(ctx context.Context, params EmbeddingParams) (Embedding, error) {
+	url := api.c.endpointURL(embeddingsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return Embedding{}, err
 	}
@@ -1,8 +1,198 @@
 package gopenai
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	errCodeContextLengthExceeded = "context_length_exceeded"
+	maxAPIErrorBodySize          = 1 << 20
+)
 
 var (
 	// ErrRequestTimeout is an error that indicates a request has timed out.
 	ErrRequestTimeout = errors.New("request timeout")
+	// ErrRequestCanceled is an error that indicates a request
+	// has been canceled through its context.
+	ErrRequestCanceled = errors.New("request canceled")
+	// ErrRequestDeadlineExceeded is an error that indicates the
+	// deadline of a request's context has been exceeded.
+	ErrRequestDeadlineExceeded = errors.New("request deadline exceeded")
+	// ErrNoChoices is an error that indicates a completion
+	// was returned without any choices.
+	ErrNoChoices = errors.New("completion has no choices")
+	// ErrResponseTruncated is an error that indicates the response
+	// was cut off because it reached the maximum number of tokens.
+	ErrResponseTruncated = errors.New("response truncated by the token limit")
+	// ErrUnsupportedStructuredOutput is an error that indicates the type
+	// a structured response is decoded into does not have the schema of
+	// an object supported by the strict mode of structured outputs.
+	ErrUnsupportedStructuredOutput = errors.New("structured output must be an object supported by strict mode")
+	// ErrUnsupportedImageType is an error that indicates the
+	// content of an image part is not a supported image.
+	ErrUnsupportedImageType = errors.New("unsupported image type")
+	// ErrFineTuneFailed is an error that indicates
+	// a fine-tune that is waited for has failed.
+	ErrFineTuneFailed = errors.New("fine-tune failed")
+	// ErrFineTuneCancelled is an error that indicates a
+	// fine-tune that is waited for has been cancelled.
+	ErrFineTuneCancelled = errors.New("fine-tune cancelled")
 )
+
+// APIError represents an error response returned by the OpenAI API.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Message is the human readable error message. If the response
+	// body is not a JSON error object it is set to the status text.
+	Message string
+	// Type is the type of the error, such as "invalid_request_error".
+	Type string
+	// Code is the machine readable error code, such
+	// as "context_length_exceeded".
+	Code string
+	// Param is the request parameter the error relates to, if any.
+	Param string
+	// RequestID is the value of the x-request-id response header.
+	RequestID string
+	// Header is the header of the response.
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+}
+
+// Error returns the string representation of the error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Status: %d | "+errMsgTpl, e.StatusCode,
+		e.Message, e.Type, e.Code, e.Param)
+}
+
+// RefusalError is returned when the model refuses to
+// generate a response in the requested format.
+type RefusalError struct {
+	// Refusal is the refusal message generated by the model.
+	Refusal string
+}
+
+// Error returns the string representation of the error.
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("model refused to respond: %s", e.Refusal)
+}
+
+// FineTuneError is returned when a fine-tune that is
+// waited for ends without succeeding.
+type FineTuneError struct {
+	// FineTune is the fine-tune in its final state.
+	FineTune FineTune
+	// Status is the final status of the fine-tune,
+	// either "failed" or "cancelled".
+	Status string
+	// Message is the message of the last error event
+	// of the fine-tune, if any.
+	Message string
+}
+
+// Error returns the string representation of the error.
+func (e *FineTuneError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("fine-tune %s %s", e.FineTune.ID, e.Status)
+	}
+
+	return fmt.Sprintf("fine-tune %s %s: %s", e.FineTune.ID, e.Status, e.Message)
+}
+
+// Unwrap returns ErrFineTuneFailed or ErrFineTuneCancelled
+// depending on the final status of the fine-tune.
+func (e *FineTuneError) Unwrap() error {
+	if e.Status == fineTuneStatusCancelled {
+		return ErrFineTuneCancelled
+	}
+
+	return ErrFineTuneFailed
+}
+
+// apiErrorObject is the error object as it is encoded in error
+// response bodies and in stream error events.
+type apiErrorObject struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Param   interface{} `json:"param"`
+	Code    interface{} `json:"code"`
+}
+
+func (o apiErrorObject) apply(e *APIError) {
+	e.Message = o.Message
+	e.Type = o.Type
+	e.Code = stringOrEmpty(o.Code)
+	e.Param = stringOrEmpty(o.Param)
+}
+
+func stringOrEmpty(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// newAPIError builds an APIError out of an error response.
+// It consumes the response body but does not close it.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get(headerNameRequestID),
+		Header:     resp.Header,
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIErrorBodySize))
+	if err != nil {
+		return apiErr
+	}
+
+	apiErr.Body = body
+
+	var errResp struct {
+		Error *apiErrorObject `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == nil {
+		return apiErr
+	}
+
+	errResp.Error.apply(apiErr)
+
+	return apiErr
+}
+
+// IsRateLimited reports whether err is an APIError
+// caused by hitting a rate limit.
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsAuthError reports whether err is an APIError caused by
+// invalid credentials or insufficient permissions.
+func IsAuthError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.StatusCode == http.StatusUnauthorized ||
+		apiErr.StatusCode == http.StatusForbidden
+}
+
+// IsContextLengthExceeded reports whether err is an APIError caused
+// by a request exceeding the model's maximum context length.
+func IsContextLengthExceeded(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.Code == errCodeContextLengthExceeded
+}
@@ -0,0 +1,86 @@
+package gopenai
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected APIError
+	}{
+		{
+			name:   "json error body",
+			status: http.StatusBadRequest,
+			body: `{"error": {"message": "too long", "type": "invalid_request_error",` +
+				` "param": "messages", "code": "context_length_exceeded"}}`,
+			expected: APIError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "too long",
+				Type:       "invalid_request_error",
+				Code:       "context_length_exceeded",
+				Param:      "messages",
+				RequestID:  "req_123",
+			},
+		},
+		{
+			name:   "null code and param",
+			status: http.StatusUnauthorized,
+			body:   `{"error": {"message": "bad key", "type": "invalid_request_error", "param": null, "code": null}}`,
+			expected: APIError{
+				StatusCode: http.StatusUnauthorized,
+				Message:    "bad key",
+				Type:       "invalid_request_error",
+				RequestID:  "req_123",
+			},
+		},
+		{
+			name:   "html body",
+			status: http.StatusBadGateway,
+			body:   `<html><body>502 Bad Gateway</body></html>`,
+			expected: APIError{
+				StatusCode: http.StatusBadGateway,
+				Message:    http.StatusText(http.StatusBadGateway),
+				RequestID:  "req_123",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rec.Header().Set(headerNameRequestID, "req_123")
+			rec.WriteHeader(tc.status)
+			_, _ = rec.WriteString(tc.body)
+
+			resp := rec.Result()
+			apiErr := newAPIError(resp)
+
+			tc.expected.Header = resp.Header
+			tc.expected.Body = []byte(tc.body)
+			assert.Equal(t, tc.expected, *apiErr)
+		})
+	}
+}
+
+func TestAPIErrorHelpers(t *testing.T) {
+	rateLimited := &APIError{StatusCode: http.StatusTooManyRequests}
+	unauthorized := &APIError{StatusCode: http.StatusUnauthorized}
+	contextLength := &APIError{StatusCode: http.StatusBadRequest, Code: errCodeContextLengthExceeded}
+	wrapped := fmt.Errorf("wrapped: %w", rateLimited)
+
+	assert.True(t, IsRateLimited(rateLimited))
+	assert.True(t, IsRateLimited(wrapped))
+	assert.False(t, IsRateLimited(unauthorized))
+	assert.True(t, IsAuthError(unauthorized))
+	assert.False(t, IsAuthError(ErrRequestTimeout))
+	assert.True(t, IsContextLengthExceeded(contextLength))
+	assert.False(t, IsContextLengthExceeded(rateLimited))
+}
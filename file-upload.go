@@ -0,0 +1,107 @@
+package gopenai
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// ErrUploadNotRewindable is returned when a request uploading a file
+// has to be retried but the reader of the file cannot seek back to
+// where the failed attempt started reading.
+var ErrUploadNotRewindable = errors.New("upload reader cannot be rewound for a retry")
+
+// notRewindableError is the error of the last attempt of a request
+// that is not retried because its upload cannot be rewound. It
+// matches both ErrUploadNotRewindable and the error of the attempt.
+type notRewindableError struct {
+	err error
+}
+
+func (e *notRewindableError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUploadNotRewindable, e.err)
+}
+
+func (e *notRewindableError) Is(target error) bool {
+	return target == ErrUploadNotRewindable
+}
+
+func (e *notRewindableError) Unwrap() error {
+	return e.err
+}
+
+// FileUpload represents a file sent in a multipart request.
+// Its content is streamed from Reader as the request is sent.
+type FileUpload struct {
+	// Reader is the reader of the file content. It is not closed by the
+	// client. Requests are only retried if it implements io.Seeker.
+	Reader io.Reader
+	// Filename is the name of the file.
+	Filename string
+	// ContentType is the MIME type of the file. It defaults
+	// to application/octet-stream when empty.
+	ContentType string
+	// Size is the size of the file, used as the total of the progress
+	// reports. It is read from the file when left 0 for uploads
+	// created from a path or reading from an *os.File.
+	Size int64
+	// OnProgress, if set, is called with the progress of the upload,
+	// from the goroutine writing the request body.
+	OnProgress ProgressFunc
+
+	// path is the path of the file to open on every attempt
+	// of the request, when the upload was created from a path.
+	path string
+}
+
+// NewFileUpload returns a FileUpload reading the file content from r.
+func NewFileUpload(r io.Reader, filename, contentType string) *FileUpload {
+	return &FileUpload{
+		Reader:      r,
+		Filename:    filename,
+		ContentType: contentType,
+	}
+}
+
+// NewFileUploadFromPath returns a FileUpload for the file at the given
+// path. The file is opened when the request is sent and closed once
+// its content has been uploaded.
+func NewFileUploadFromPath(filePath string) *FileUpload {
+	return &FileUpload{
+		Filename: path.Base(filePath),
+		path:     filePath,
+	}
+}
+
+// size returns the size of the file read from the given reader.
+func (u *FileUpload) size(r io.Reader) int64 {
+	if u.Size > 0 {
+		return u.Size
+	}
+
+	file, ok := r.(*os.File)
+	if !ok {
+		file, ok = u.Reader.(*os.File)
+	}
+
+	if !ok {
+		return 0
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
+
+func (u *FileUpload) contentType() string {
+	if u.ContentType == "" {
+		return "application/octet-stream"
+	}
+
+	return u.ContentType
+}
@@ -1,13 +1,36 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
-const filesAPIEndpoint = "/files"
+const (
+	filesAPIEndpoint = "/files"
+
+	headerNameRange        = "Range"
+	headerNameAcceptRanges = "Accept-Ranges"
+	headerNameContentRange = "Content-Range"
+)
+
+var (
+	// ErrDownloadSizeMismatch is returned when the number of bytes
+	// downloaded differs from the size of the file.
+	ErrDownloadSizeMismatch = errors.New("downloaded size does not match the file size")
+	// ErrRangeNotSupported is returned when a download has to resume
+	// from an offset but the server does not support range requests.
+	ErrRangeNotSupported = errors.New("server does not support range requests")
+	// ErrRangeMismatch is returned when a resumed download receives
+	// a range that does not start where the download stopped.
+	ErrRangeMismatch = errors.New("server returned a different range than requested")
+)
 
 // File represents a file in the OpenAI API
 type File struct {
@@ -25,12 +48,44 @@ type File struct {
 
 // FileParams contains the parameters to create a new file in the OpenAI API
 type FileParams struct {
-	// File is the file content
-	File string `mapstructure:"file"`
+	// File is the file to upload
+	File *FileUpload `mapstructure:"file"`
 	// Purpose is the purpose of the file
 	Purpose string `mapstructure:"purpose"`
 }
 
+// FileListParams holds the parameters for listing files.
+type FileListParams struct {
+	ListParams
+	// Purpose filters the files by their purpose.
+	Purpose string
+}
+
+func (p FileListParams) query() url.Values {
+	query := p.ListParams.query()
+	if p.Purpose != "" {
+		query.Set("purpose", p.Purpose)
+	}
+
+	return query
+}
+
+// DownloadOptions holds the options of a file download.
+type DownloadOptions struct {
+	// Offset is the number of bytes of the file already downloaded,
+	// from which the download resumes with a range request.
+	Offset int64
+	// MaxResumes is the number of times a download interrupted by a
+	// connection error is resumed from where it stopped, if the
+	// server supports range requests.
+	MaxResumes int
+	// VerifySize makes the download fetch the file metadata and
+	// check that the downloaded size matches File.Bytes.
+	VerifySize bool
+	// OnProgress, if set, is called with the progress of the download.
+	OnProgress ProgressFunc
+}
+
 // DeletedFile represents a file that has been deleted from the OpenAI API
 type DeletedFile struct {
 	// ID is the identifier of the file
@@ -43,14 +98,29 @@ type DeletedFile struct {
 type FilesAPI interface {
 	// GetAll returns all the files in the OpenAI API
 	GetAll() ([]File, error)
+	// GetAllWithContext is like GetAll but uses the given context
+	GetAllWithContext(ctx context.Context) ([]File, error)
+	// List returns a pager over the files, fetching their pages lazily
+	List(ctx context.Context, params FileListParams) *Pager[File]
 	// GetByID returns a file by its identifier
 	GetByID(id string) (File, error)
+	// GetByIDWithContext is like GetByID but uses the given context
+	GetByIDWithContext(ctx context.Context, id string) (File, error)
 	// Create creates a new file in the OpenAI API
 	Create(FileParams) (File, error)
+	// CreateWithContext is like Create but uses the given context
+	CreateWithContext(context.Context, FileParams) (File, error)
 	// DeleteByID deletes a file by its identifier
 	DeleteByID(id string) (DeletedFile, error)
+	// DeleteByIDWithContext is like DeleteByID but uses the given context
+	DeleteByIDWithContext(ctx context.Context, id string) (DeletedFile, error)
 	// DownloadByID downloads a file by its identifier
 	DownloadByID(id string, dst io.Writer) error
+	// DownloadByIDWithContext is like DownloadByID but uses the given context
+	DownloadByIDWithContext(ctx context.Context, id string, dst io.Writer) error
+	// DownloadByIDWithOptions is like DownloadByIDWithContext but
+	// reports progress, verifies and resumes the download as configured
+	DownloadByIDWithOptions(ctx context.Context, id string, dst io.Writer, opts DownloadOptions) error
 }
 
 type filesAPI struct {
@@ -58,8 +128,12 @@ type filesAPI struct {
 }
 
 func (api filesAPI) GetAll() ([]File, error) {
-	url := fmt.Sprintf("%s%s", baseURL, filesAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetAllWithContext(context.Background())
+}
+
+func (api filesAPI) GetAllWithContext(ctx context.Context) ([]File, error) {
+	url := api.c.endpointURL(filesAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +149,19 @@ func (api filesAPI) GetAll() ([]File, error) {
 	return response.Data, nil
 }
 
+func (api filesAPI) List(ctx context.Context, params FileListParams) *Pager[File] {
+	url := api.c.endpointURL(filesAPIEndpoint)
+
+	return newPager(ctx, api.c, url, params.query(), func(f File) string { return f.ID })
+}
+
 func (api filesAPI) GetByID(id string) (File, error) {
-	url := fmt.Sprintf("%s%s/%s", baseURL, filesAPIEndpoint, id)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetByIDWithContext(context.Background(), id)
+}
+
+func (api filesAPI) GetByIDWithContext(ctx context.Context, id string) (File, error) {
+	url := api.c.endpointURL(filesAPIEndpoint, id)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return File{}, err
 	}
@@ -91,8 +175,12 @@ func (api filesAPI) GetByID(id string) (File, error) {
 }
 
 func (api filesAPI) DeleteByID(id string) (DeletedFile, error) {
-	url := fmt.Sprintf("%s%s/%s", baseURL, filesAPIEndpoint, id)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodDelete, nil)
+	return api.DeleteByIDWithContext(context.Background(), id)
+}
+
+func (api filesAPI) DeleteByIDWithContext(ctx context.Context, id string) (DeletedFile, error) {
+	url := api.c.endpointURL(filesAPIEndpoint, id)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodDelete, nil)
 	if err != nil {
 		return DeletedFile{}, err
 	}
@@ -106,13 +194,17 @@ func (api filesAPI) DeleteByID(id string) (DeletedFile, error) {
 }
 
 func (api filesAPI) Create(params FileParams) (File, error) {
-	url := fmt.Sprintf("%s%s", baseURL, filesAPIEndpoint)
-	data, contentType, err := structToMultipartFormData(params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api filesAPI) CreateWithContext(ctx context.Context, params FileParams) (File, error) {
+	url := api.c.endpointURL(filesAPIEndpoint)
+	body, contentType, err := structToMultipartBody(params)
 	if err != nil {
 		return File{}, err
 	}
 
-	r, err := api.c.getRequestResponse(url, http.MethodPost, data, contentType)
+	r, err := api.c.getRequestResponse(ctx, url, http.MethodPost, body, contentType)
 	if err != nil {
 		return File{}, err
 	}
@@ -126,11 +218,129 @@ func (api filesAPI) Create(params FileParams) (File, error) {
 }
 
 func (api filesAPI) DownloadByID(id string, dst io.Writer) error {
-	url := fmt.Sprintf("%s%s/%s/content", baseURL, filesAPIEndpoint, id)
-	err := api.c.streamRequestResponse(url, http.MethodGet, nil, "", dst)
-	if err != nil {
-		return err
+	return api.DownloadByIDWithContext(context.Background(), id, dst)
+}
+
+func (api filesAPI) DownloadByIDWithContext(ctx context.Context, id string, dst io.Writer) error {
+	return api.DownloadByIDWithOptions(ctx, id, dst, DownloadOptions{})
+}
+
+func (api filesAPI) DownloadByIDWithOptions(ctx context.Context, id string, dst io.Writer, opts DownloadOptions) error {
+	var total int64
+	if opts.VerifySize {
+		file, err := api.GetByIDWithContext(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		total = int64(file.Bytes)
+	}
+
+	url := api.c.endpointURL(filesAPIEndpoint, id, "content")
+	done := opts.Offset
+	var progress *progressReader
+
+	for resumes := 0; ; resumes++ {
+		var header http.Header
+		if done > 0 {
+			header = http.Header{headerNameRange: {fmt.Sprintf("bytes=%d-", done)}}
+		}
+
+		resp, err := api.c.getHTTPResponse(ctx, url, http.MethodGet, header, nil, "")
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK && done > 0:
+			resp.Body.Close()
+
+			return ErrRangeNotSupported
+		case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+			err := newAPIError(resp)
+			resp.Body.Close()
+
+			return err
+		}
+
+		if resp.StatusCode == http.StatusPartialContent {
+			start, size, err := parseContentRange(resp.Header.Get(headerNameContentRange))
+			if err == nil && start != done {
+				err = fmt.Errorf("%w: requested offset %d, got %d", ErrRangeMismatch, done, start)
+			}
+
+			if err != nil {
+				resp.Body.Close()
+
+				return err
+			}
+
+			if total == 0 && size > 0 {
+				total = size
+			}
+		}
+
+		if total == 0 && resp.ContentLength > 0 {
+			total = done + resp.ContentLength
+		}
+
+		if progress == nil {
+			progress = newProgressReader(resp.Body, done, total, opts.OnProgress)
+		} else {
+			progress.r = resp.Body
+		}
+
+		_, err = io.Copy(dst, progress)
+		resp.Body.Close()
+		done = progress.done
+
+		if err == nil {
+			break
+		}
+
+		readErr := progress.err
+		progress.err = nil
+
+		// a partial content response proves range support
+		// even when it does not repeat the Accept-Ranges header
+		rangesSupported := resp.StatusCode == http.StatusPartialContent ||
+			resp.Header.Get(headerNameAcceptRanges) == "bytes"
+
+		if readErr == nil || ctx.Err() != nil || resumes >= opts.MaxResumes || !rangesSupported {
+			return requestError(ctx, err)
+		}
+	}
+
+	if opts.VerifySize && done != total {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrDownloadSizeMismatch, total, done)
 	}
 
 	return nil
 }
+
+// parseContentRange returns the start of the range and the size of the
+// file, or -1 if it is unknown, from a "bytes start-end/size" header.
+func parseContentRange(value string) (int64, int64, error) {
+	invalidErr := fmt.Errorf("%w: invalid Content-Range %q", ErrRangeMismatch, value)
+
+	rangeSpec, size, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, invalidErr
+	}
+
+	var start, end int64
+	if _, err := fmt.Sscanf(rangeSpec, "bytes %d-%d", &start, &end); err != nil {
+		return 0, 0, invalidErr
+	}
+
+	if size == "*" {
+		return start, -1, nil
+	}
+
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, invalidErr
+	}
+
+	return start, total, nil
+}
@@ -0,0 +1,275 @@
+package gopenai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFileContent = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newTestDownloadServer serves testFileContent, cutting the connection
+// after half of the requested content for the first interrupts requests,
+// and reporting the file as fileBytes bytes long. Interrupted range
+// requests are answered without an Accept-Ranges header.
+func newTestDownloadServer(t *testing.T, interrupts, fileBytes int, ranges *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/content") {
+			w.Header().Set(headerNameContentType, contentTypeJSON)
+			_, _ = io.WriteString(w, `{"id": "file-1", "bytes": `+strconv.Itoa(fileBytes)+`}`)
+
+			return
+		}
+
+		*ranges = append(*ranges, r.Header.Get(headerNameRange))
+		if interrupts > 0 {
+			interrupts--
+
+			var start int
+			if r.Header.Get(headerNameRange) == "" {
+				w.Header().Set(headerNameAcceptRanges, "bytes")
+			} else {
+				_, err := fmt.Sscanf(r.Header.Get(headerNameRange), "bytes=%d-", &start)
+				require.NoError(t, err)
+
+				w.Header().Set(headerNameContentRange, fmt.Sprintf("bytes %d-%d/%d", start, len(testFileContent)-1, len(testFileContent)))
+			}
+
+			remaining := testFileContent[start:]
+
+			w.Header().Set("Content-Length", strconv.Itoa(len(remaining)))
+			if start > 0 {
+				w.WriteHeader(http.StatusPartialContent)
+			}
+
+			_, _ = io.WriteString(w, remaining[:len(remaining)/2])
+			w.(http.Flusher).Flush()
+
+			panic(http.ErrAbortHandler)
+		}
+
+		http.ServeContent(w, r, "file.jsonl", time.Time{}, strings.NewReader(testFileContent))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestFilesDownloadByIDWithOptions(t *testing.T) {
+	half := len(testFileContent) / 2
+
+	testCases := []struct {
+		name           string
+		interrupts     int
+		fileBytes      int
+		opts           DownloadOptions
+		expectedRanges []string
+		expectedData   string
+		expectedErr    error
+	}{
+		{
+			name:           "verified download",
+			fileBytes:      len(testFileContent),
+			opts:           DownloadOptions{VerifySize: true},
+			expectedRanges: []string{""},
+			expectedData:   testFileContent,
+		},
+		{
+			name:           "size mismatch",
+			fileBytes:      len(testFileContent) + 1,
+			opts:           DownloadOptions{VerifySize: true},
+			expectedRanges: []string{""},
+			expectedData:   testFileContent,
+			expectedErr:    ErrDownloadSizeMismatch,
+		},
+		{
+			name:           "resumed after interruption",
+			interrupts:     1,
+			opts:           DownloadOptions{MaxResumes: 1},
+			expectedRanges: []string{"", "bytes=" + strconv.Itoa(half) + "-"},
+			expectedData:   testFileContent,
+		},
+		{
+			name:       "resumed after a partial content interruption",
+			interrupts: 2,
+			opts:       DownloadOptions{MaxResumes: 2},
+			expectedRanges: []string{
+				"",
+				"bytes=" + strconv.Itoa(half) + "-",
+				"bytes=" + strconv.Itoa(half+half/2) + "-",
+			},
+			expectedData: testFileContent,
+		},
+		{
+			name:           "resumed from offset",
+			opts:           DownloadOptions{Offset: 10},
+			expectedRanges: []string{"bytes=10-"},
+			expectedData:   testFileContent[10:],
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ranges []string
+			server := newTestDownloadServer(t, tc.interrupts, tc.fileBytes, &ranges)
+
+			var dst bytes.Buffer
+			err := New(Config{BaseURL: server.URL}).Files().DownloadByIDWithOptions(context.Background(), "file-1", &dst, tc.opts)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedData, dst.String())
+			assert.Equal(t, tc.expectedRanges, ranges)
+		})
+	}
+}
+
+func TestFilesDownloadByIDInterrupted(t *testing.T) {
+	var ranges []string
+	server := newTestDownloadServer(t, 1, 0, &ranges)
+
+	var dst bytes.Buffer
+	err := New(Config{BaseURL: server.URL}).Files().DownloadByID("file-1", &dst)
+	assert.Error(t, err)
+	assert.Equal(t, testFileContent[:len(testFileContent)/2], dst.String())
+}
+
+func TestFilesDownloadByIDProgress(t *testing.T) {
+	var ranges []string
+	server := newTestDownloadServer(t, 1, 0, &ranges)
+
+	var reports []Progress
+	opts := DownloadOptions{
+		MaxResumes: 1,
+		OnProgress: func(p Progress) { reports = append(reports, p) },
+	}
+
+	err := New(Config{BaseURL: server.URL}).Files().DownloadByIDWithOptions(context.Background(), "file-1", io.Discard, opts)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, reports)
+	last := reports[len(reports)-1]
+	assert.Equal(t, int64(len(testFileContent)), last.BytesDone)
+	assert.Equal(t, int64(len(testFileContent)), last.BytesTotal)
+	assert.Zero(t, last.ETA)
+}
+
+func TestFilesCreateProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, `{"id": "file-1"}`)
+	}))
+	defer server.Close()
+
+	var reports []Progress
+	upload := NewFileUploadFromPath("./.fixture/test-file.jsonl")
+	upload.OnProgress = func(p Progress) { reports = append(reports, p) }
+
+	_, err := New(Config{BaseURL: server.URL}).Files().Create(FileParams{File: upload, Purpose: "fine-tune"})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, reports)
+	assert.Equal(t, int64(69), reports[len(reports)-1].BytesDone)
+	assert.Equal(t, int64(69), reports[len(reports)-1].BytesTotal)
+}
+
+func TestFilesDownloadByIDContentRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		contentRange  string
+		expectedTotal int64
+		expectedErr   error
+	}{
+		{
+			name:          "matching range",
+			contentRange:  "bytes 10-35/36",
+			expectedTotal: int64(len(testFileContent)),
+		},
+		{
+			name:          "unknown size",
+			contentRange:  "bytes 10-35/*",
+			expectedTotal: 0,
+		},
+		{
+			name:         "different start",
+			contentRange: "bytes 0-35/36",
+			expectedErr:  ErrRangeMismatch,
+		},
+		{
+			name:        "missing header",
+			expectedErr: ErrRangeMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "bytes=10-", r.Header.Get(headerNameRange))
+
+				if tc.contentRange != "" {
+					w.Header().Set(headerNameContentRange, tc.contentRange)
+				}
+
+				// flushed before the body so that no Content-Length is sent
+				w.WriteHeader(http.StatusPartialContent)
+				w.(http.Flusher).Flush()
+				_, _ = io.WriteString(w, testFileContent[10:])
+			}))
+			defer server.Close()
+
+			var (
+				dst   bytes.Buffer
+				total int64
+			)
+
+			opts := DownloadOptions{
+				Offset:     10,
+				OnProgress: func(p Progress) { total = p.BytesTotal },
+			}
+
+			err := New(Config{BaseURL: server.URL}).Files().DownloadByIDWithOptions(context.Background(), "file-1", &dst, opts)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Zero(t, dst.Len())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testFileContent[10:], dst.String())
+			assert.Equal(t, tc.expectedTotal, total)
+		})
+	}
+}
+
+func TestFilesDownloadByIDRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < len(testFileContent); i += 9 {
+			_, _ = io.WriteString(w, testFileContent[i:i+9])
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Millisecond * 40)
+		}
+	}))
+	defer server.Close()
+
+	var dst bytes.Buffer
+	err := New(Config{BaseURL: server.URL, RequestTimeout: time.Millisecond * 50}).Files().DownloadByID("file-1", &dst)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+}
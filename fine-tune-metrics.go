@@ -0,0 +1,455 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// fineTuneMetricColumns maps the columns of the result files of both
+// the legacy fine-tunes and the fine-tuning jobs to the canonical
+// column names used when exporting metrics.
+var fineTuneMetricColumns = map[string]string{
+	"step":                           "step",
+	"elapsed_tokens":                 "elapsed_tokens",
+	"elapsed_examples":               "elapsed_examples",
+	"train_loss":                     "train_loss",
+	"training_loss":                  "train_loss",
+	"train_accuracy":                 "train_accuracy",
+	"train_mean_token_accuracy":      "train_accuracy",
+	"training_token_accuracy":        "train_accuracy",
+	"train_sequence_accuracy":        "train_sequence_accuracy",
+	"training_sequence_accuracy":     "train_sequence_accuracy",
+	"valid_loss":                     "valid_loss",
+	"validation_loss":                "valid_loss",
+	"valid_accuracy":                 "valid_accuracy",
+	"valid_mean_token_accuracy":      "valid_accuracy",
+	"validation_token_accuracy":      "valid_accuracy",
+	"valid_sequence_accuracy":        "valid_sequence_accuracy",
+	"validation_sequence_accuracy":   "valid_sequence_accuracy",
+	"full_valid_loss":                "full_valid_loss",
+	"full_valid_mean_token_accuracy": "full_valid_accuracy",
+}
+
+// fineTuneMetricColumnOrder is the order of the canonical columns.
+var fineTuneMetricColumnOrder = []string{
+	"step",
+	"elapsed_tokens",
+	"elapsed_examples",
+	"train_loss",
+	"train_accuracy",
+	"train_sequence_accuracy",
+	"valid_loss",
+	"valid_accuracy",
+	"valid_sequence_accuracy",
+	"full_valid_loss",
+	"full_valid_accuracy",
+}
+
+// FineTuneMetricRow represents a step of a fine-tune, as
+// reported by a row of its result file. Metrics that are not
+// reported at the step, such as validation metrics, are nil.
+type FineTuneMetricRow struct {
+	Step                  int      `json:"step"`
+	ElapsedTokens         *int     `json:"elapsed_tokens,omitempty"`
+	ElapsedExamples       *int     `json:"elapsed_examples,omitempty"`
+	TrainLoss             *float64 `json:"train_loss,omitempty"`
+	TrainAccuracy         *float64 `json:"train_accuracy,omitempty"`
+	TrainSequenceAccuracy *float64 `json:"train_sequence_accuracy,omitempty"`
+	ValidLoss             *float64 `json:"valid_loss,omitempty"`
+	ValidAccuracy         *float64 `json:"valid_accuracy,omitempty"`
+	ValidSequenceAccuracy *float64 `json:"valid_sequence_accuracy,omitempty"`
+	FullValidLoss         *float64 `json:"full_valid_loss,omitempty"`
+	FullValidAccuracy     *float64 `json:"full_valid_accuracy,omitempty"`
+	// Extra holds the metrics of the columns that are not mapped
+	// to a field, such as the classification metrics.
+	Extra map[string]float64 `json:"extra,omitempty"`
+	// ExtraText holds the values of the columns that are not mapped
+	// to a field and are not numbers, which are kept as they are.
+	ExtraText map[string]string `json:"extra_text,omitempty"`
+}
+
+// intFields returns the integer fields of the row by canonical column.
+func (r *FineTuneMetricRow) intFields() map[string]**int {
+	return map[string]**int{
+		"elapsed_tokens":   &r.ElapsedTokens,
+		"elapsed_examples": &r.ElapsedExamples,
+	}
+}
+
+// floatFields returns the metric fields of the row by canonical column.
+func (r *FineTuneMetricRow) floatFields() map[string]**float64 {
+	return map[string]**float64{
+		"train_loss":              &r.TrainLoss,
+		"train_accuracy":          &r.TrainAccuracy,
+		"train_sequence_accuracy": &r.TrainSequenceAccuracy,
+		"valid_loss":              &r.ValidLoss,
+		"valid_accuracy":          &r.ValidAccuracy,
+		"valid_sequence_accuracy": &r.ValidSequenceAccuracy,
+		"full_valid_loss":         &r.FullValidLoss,
+		"full_valid_accuracy":     &r.FullValidAccuracy,
+	}
+}
+
+func (r *FineTuneMetricRow) set(column, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if column == "step" {
+		step, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		r.Step = step
+
+		return nil
+	}
+
+	if field, ok := r.intFields()[column]; ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		*field = &v
+
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+
+	if field, ok := r.floatFields()[column]; ok {
+		if err != nil {
+			return err
+		}
+
+		*field = &v
+
+		return nil
+	}
+
+	if err != nil {
+		if r.ExtraText == nil {
+			r.ExtraText = map[string]string{}
+		}
+
+		r.ExtraText[column] = value
+
+		return nil
+	}
+
+	if r.Extra == nil {
+		r.Extra = map[string]float64{}
+	}
+
+	r.Extra[column] = v
+
+	return nil
+}
+
+// get returns the value of the given canonical or extra
+// column, formatted for a CSV file, or "" if it is not set.
+func (r *FineTuneMetricRow) get(column string) string {
+	if column == "step" {
+		return strconv.Itoa(r.Step)
+	}
+
+	if field, ok := r.intFields()[column]; ok {
+		if *field == nil {
+			return ""
+		}
+
+		return strconv.Itoa(**field)
+	}
+
+	if field, ok := r.floatFields()[column]; ok {
+		if *field == nil {
+			return ""
+		}
+
+		return strconv.FormatFloat(**field, 'g', -1, 64)
+	}
+
+	if v, ok := r.Extra[column]; ok {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	return r.ExtraText[column]
+}
+
+// FineTuneMetrics holds the rows of a fine-tune result file.
+type FineTuneMetrics []FineTuneMetricRow
+
+// FineTuneEpochMetrics represents the aggregated metrics of an
+// epoch. The means are nil when no step of the epoch reports them.
+type FineTuneEpochMetrics struct {
+	Epoch             int      `json:"epoch"`
+	FirstStep         int      `json:"first_step"`
+	LastStep          int      `json:"last_step"`
+	MeanTrainLoss     *float64 `json:"mean_train_loss,omitempty"`
+	MeanTrainAccuracy *float64 `json:"mean_train_accuracy,omitempty"`
+	MeanValidLoss     *float64 `json:"mean_valid_loss,omitempty"`
+	MeanValidAccuracy *float64 `json:"mean_valid_accuracy,omitempty"`
+}
+
+// ParseFineTuneMetrics parses a fine-tune result file. It supports the
+// columns of the result files of both the legacy fine-tunes and the
+// fine-tuning jobs, which are mapped to the same fields.
+func ParseFineTuneMetrics(r io.Reader) (FineTuneMetrics, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = name
+		if canonical, ok := fineTuneMetricColumns[name]; ok {
+			columns[i] = canonical
+		}
+	}
+
+	var metrics FineTuneMetrics
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return metrics, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		var row FineTuneMetricRow
+		for i, value := range record {
+			if err := row.set(columns[i], value); err != nil {
+				line, _ := reader.FieldPos(i)
+
+				return nil, fmt.Errorf("invalid %s value %q at line %d: %w", header[i], value, line, err)
+			}
+		}
+
+		metrics = append(metrics, row)
+	}
+}
+
+// DownloadFineTuneMetrics downloads the result file with the given ID
+// and parses it with ParseFineTuneMetrics.
+func DownloadFineTuneMetrics(ctx context.Context, files FilesAPI, fileID string) (FineTuneMetrics, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(files.DownloadByIDWithContext(ctx, fileID, pw))
+	}()
+
+	metrics, err := ParseFineTuneMetrics(pr)
+	pr.Close()
+
+	return metrics, err
+}
+
+// FinalTrainLoss returns the training loss of the last
+// step reporting it, and whether there is such a step.
+func (m FineTuneMetrics) FinalTrainLoss() (float64, bool) {
+	return m.final(func(r FineTuneMetricRow) *float64 { return r.TrainLoss })
+}
+
+// FinalValidLoss returns the validation loss of the last step
+// reporting it, and whether there is such a step. The loss on the
+// full validation set is preferred over the one on a batch of it.
+func (m FineTuneMetrics) FinalValidLoss() (float64, bool) {
+	return m.final(validLoss)
+}
+
+// validLoss returns the validation loss of the row, preferring the
+// loss on the full validation set, or nil if it reports neither.
+func validLoss(r FineTuneMetricRow) *float64 {
+	if r.FullValidLoss != nil {
+		return r.FullValidLoss
+	}
+
+	return r.ValidLoss
+}
+
+func (m FineTuneMetrics) final(metric func(FineTuneMetricRow) *float64) (float64, bool) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if v := metric(m[i]); v != nil {
+			return *v, true
+		}
+	}
+
+	return 0, false
+}
+
+// BestValidStep returns the step with the lowest validation loss,
+// compared as by FinalValidLoss, and whether any step reports one.
+func (m FineTuneMetrics) BestValidStep() (FineTuneMetricRow, bool) {
+	best, found := FineTuneMetricRow{}, false
+	for _, row := range m {
+		if loss := validLoss(row); loss != nil && (!found || *loss < *validLoss(best)) {
+			best, found = row, true
+		}
+	}
+
+	return best, found
+}
+
+// Epochs aggregates the metrics of the steps by epoch, given the
+// number of steps per epoch. Steps are numbered from 1, and steps
+// numbered 0 or lower, if any, are counted in the first epoch. The
+// validation loss of each step is taken as by FinalValidLoss.
+func (m FineTuneMetrics) Epochs(stepsPerEpoch int) ([]FineTuneEpochMetrics, error) {
+	if stepsPerEpoch <= 0 {
+		return nil, errors.New("steps per epoch must be positive")
+	}
+
+	type sums struct {
+		trainLoss, trainAccuracy, validLoss, validAccuracy meanSum
+	}
+
+	var (
+		epochs []FineTuneEpochMetrics
+		totals []sums
+	)
+
+	for _, row := range m {
+		epoch := (row.Step-1)/stepsPerEpoch + 1
+		if epoch < 1 {
+			epoch = 1
+		}
+
+		if len(epochs) == 0 || epochs[len(epochs)-1].Epoch != epoch {
+			epochs = append(epochs, FineTuneEpochMetrics{Epoch: epoch, FirstStep: row.Step})
+			totals = append(totals, sums{})
+		}
+
+		epochs[len(epochs)-1].LastStep = row.Step
+
+		total := &totals[len(totals)-1]
+		total.trainLoss.add(row.TrainLoss)
+		total.trainAccuracy.add(row.TrainAccuracy)
+		total.validLoss.add(validLoss(row))
+		total.validAccuracy.add(row.ValidAccuracy)
+	}
+
+	for i := range epochs {
+		epochs[i].MeanTrainLoss = totals[i].trainLoss.mean()
+		epochs[i].MeanTrainAccuracy = totals[i].trainAccuracy.mean()
+		epochs[i].MeanValidLoss = totals[i].validLoss.mean()
+		epochs[i].MeanValidAccuracy = totals[i].validAccuracy.mean()
+	}
+
+	return epochs, nil
+}
+
+type meanSum struct {
+	sum   float64
+	count int
+}
+
+func (s *meanSum) add(v *float64) {
+	if v != nil {
+		s.sum += *v
+		s.count++
+	}
+}
+
+func (s meanSum) mean() *float64 {
+	if s.count == 0 {
+		return nil
+	}
+
+	mean := s.sum / float64(s.count)
+
+	return &mean
+}
+
+// WriteCSV writes the metrics as a CSV file with the canonical
+// column names, leaving out the columns no step reports.
+func (m FineTuneMetrics) WriteCSV(w io.Writer) error {
+	columns := m.columns()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range m {
+		for i, column := range columns {
+			record[i] = row.get(column)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WriteJSON writes the metrics as a JSON array of rows.
+func (m FineTuneMetrics) WriteJSON(w io.Writer) error {
+	rows := m
+	if rows == nil {
+		rows = FineTuneMetrics{}
+	}
+
+	return json.NewEncoder(w).Encode(rows)
+}
+
+// columns returns the canonical columns reported by any step,
+// followed by the extra columns sorted by name.
+func (m FineTuneMetrics) columns() []string {
+	reported := map[string]bool{"step": true}
+	var extra []string
+
+	for _, row := range m {
+		for _, column := range fineTuneMetricColumnOrder {
+			if row.get(column) != "" {
+				reported[column] = true
+			}
+		}
+
+		for column := range row.Extra {
+			if !reported[column] {
+				reported[column] = true
+				extra = append(extra, column)
+			}
+		}
+
+		for column := range row.ExtraText {
+			if !reported[column] {
+				reported[column] = true
+				extra = append(extra, column)
+			}
+		}
+	}
+
+	var columns []string
+	for _, column := range fineTuneMetricColumnOrder {
+		if reported[column] {
+			columns = append(columns, column)
+		}
+	}
+
+	sort.Strings(extra)
+
+	return append(columns, extra...)
+}
@@ -0,0 +1,173 @@
+package gopenai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFineTuneResults = `step,train_loss,train_accuracy,valid_loss,valid_mean_token_accuracy,classification/accuracy
+1,2.5,0.4,,,
+2,2.0,0.5,1.8,0.55,
+3,1.5,0.6,,,0.7
+4,1.0,0.7,1.9,0.6,
+`
+
+const testLegacyFineTuneResults = `step,elapsed_tokens,elapsed_examples,training_loss,training_sequence_accuracy,training_token_accuracy,validation_loss
+1,100,1,0.9,0.0,0.5,
+2,200,2,0.7,0.5,0.75,0.8
+`
+
+func TestParseFineTuneMetrics(t *testing.T) {
+	metrics, err := ParseFineTuneMetrics(strings.NewReader(testFineTuneResults))
+	require.NoError(t, err)
+	require.Len(t, metrics, 4)
+
+	assert.Equal(t, FineTuneMetricRow{
+		Step:          2,
+		TrainLoss:     Float64(2.0),
+		TrainAccuracy: Float64(0.5),
+		ValidLoss:     Float64(1.8),
+		ValidAccuracy: Float64(0.55),
+	}, metrics[1])
+	assert.Equal(t, map[string]float64{"classification/accuracy": 0.7}, metrics[2].Extra)
+
+	legacy, err := ParseFineTuneMetrics(strings.NewReader(testLegacyFineTuneResults))
+	require.NoError(t, err)
+	assert.Equal(t, FineTuneMetricRow{
+		Step:                  2,
+		ElapsedTokens:         Int(200),
+		ElapsedExamples:       Int(2),
+		TrainLoss:             Float64(0.7),
+		TrainAccuracy:         Float64(0.75),
+		TrainSequenceAccuracy: Float64(0.5),
+		ValidLoss:             Float64(0.8),
+	}, legacy[1])
+
+	_, err = ParseFineTuneMetrics(strings.NewReader("step,train_loss\n1,nan?\n"))
+	assert.ErrorContains(t, err, "at line 2")
+
+	_, err = ParseFineTuneMetrics(strings.NewReader("step,train_loss,phase\n1,0.5,\"warm\nup\"\n2,nan?,done\n"))
+	assert.ErrorContains(t, err, "at line 4")
+
+	text, err := ParseFineTuneMetrics(strings.NewReader("step,train_loss,phase\n1,0.5,warmup\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"phase": "warmup"}, text[0].ExtraText)
+
+	var csvData bytes.Buffer
+	require.NoError(t, text.WriteCSV(&csvData))
+	assert.Equal(t, "step,train_loss,phase\n1,0.5,warmup\n", csvData.String())
+}
+
+func TestFineTuneMetricsSummaries(t *testing.T) {
+	metrics, err := ParseFineTuneMetrics(strings.NewReader(testFineTuneResults))
+	require.NoError(t, err)
+
+	trainLoss, ok := metrics.FinalTrainLoss()
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, trainLoss)
+
+	validLoss, ok := metrics.FinalValidLoss()
+	assert.True(t, ok)
+	assert.Equal(t, 1.9, validLoss)
+
+	best, ok := metrics.BestValidStep()
+	assert.True(t, ok)
+	assert.Equal(t, 2, best.Step)
+
+	epochs, err := metrics.Epochs(2)
+	require.NoError(t, err)
+	require.Len(t, epochs, 2)
+	assert.Equal(t, []int{1, 1, 2}, []int{epochs[0].Epoch, epochs[0].FirstStep, epochs[0].LastStep})
+	assert.Equal(t, []int{2, 3, 4}, []int{epochs[1].Epoch, epochs[1].FirstStep, epochs[1].LastStep})
+	assert.InDelta(t, 2.25, *epochs[0].MeanTrainLoss, 1e-9)
+	assert.InDelta(t, 0.65, *epochs[1].MeanTrainAccuracy, 1e-9)
+	assert.InDelta(t, 1.9, *epochs[1].MeanValidLoss, 1e-9)
+	assert.InDelta(t, 0.6, *epochs[1].MeanValidAccuracy, 1e-9)
+
+	_, err = metrics.Epochs(0)
+	assert.Error(t, err)
+
+	_, ok = FineTuneMetrics{}.BestValidStep()
+	assert.False(t, ok)
+}
+
+func TestFineTuneMetricsFullValidLoss(t *testing.T) {
+	metrics := FineTuneMetrics{
+		{Step: 1, ValidLoss: Float64(1.0), FullValidLoss: Float64(1.5)},
+		{Step: 2, ValidLoss: Float64(1.2)},
+		{Step: 3, ValidLoss: Float64(0.9), FullValidLoss: Float64(1.4)},
+	}
+
+	validLoss, ok := metrics.FinalValidLoss()
+	assert.True(t, ok)
+	assert.Equal(t, 1.4, validLoss)
+
+	best, ok := metrics.BestValidStep()
+	assert.True(t, ok)
+	assert.Equal(t, 2, best.Step)
+
+	epochs, err := metrics.Epochs(2)
+	require.NoError(t, err)
+	require.Len(t, epochs, 2)
+	assert.InDelta(t, 1.35, *epochs[0].MeanValidLoss, 1e-9)
+	assert.InDelta(t, 1.4, *epochs[1].MeanValidLoss, 1e-9)
+}
+
+func TestFineTuneMetricsEpochsStepZero(t *testing.T) {
+	metrics := FineTuneMetrics{
+		{Step: 0, TrainLoss: Float64(3.0)},
+		{Step: 1, TrainLoss: Float64(2.0)},
+		{Step: 2, TrainLoss: Float64(1.0)},
+	}
+
+	epochs, err := metrics.Epochs(2)
+	require.NoError(t, err)
+	require.Len(t, epochs, 1)
+	assert.Equal(t, []int{1, 0, 2}, []int{epochs[0].Epoch, epochs[0].FirstStep, epochs[0].LastStep})
+	assert.InDelta(t, 2.0, *epochs[0].MeanTrainLoss, 1e-9)
+}
+
+func TestFineTuneMetricsExport(t *testing.T) {
+	metrics, err := ParseFineTuneMetrics(strings.NewReader(testFineTuneResults))
+	require.NoError(t, err)
+
+	var csvData bytes.Buffer
+	require.NoError(t, metrics.WriteCSV(&csvData))
+	assert.Equal(t, ""+
+		"step,train_loss,train_accuracy,valid_loss,valid_accuracy,classification/accuracy\n"+
+		"1,2.5,0.4,,,\n"+
+		"2,2,0.5,1.8,0.55,\n"+
+		"3,1.5,0.6,,,0.7\n"+
+		"4,1,0.7,1.9,0.6,\n", csvData.String())
+
+	reparsed, err := ParseFineTuneMetrics(&csvData)
+	require.NoError(t, err)
+	assert.Equal(t, metrics, reparsed)
+
+	var jsonData bytes.Buffer
+	require.NoError(t, metrics[:2].WriteJSON(&jsonData))
+	assert.JSONEq(t, `[
+		{"step": 1, "train_loss": 2.5, "train_accuracy": 0.4},
+		{"step": 2, "train_loss": 2, "train_accuracy": 0.5, "valid_loss": 1.8, "valid_accuracy": 0.55}
+	]`, jsonData.String())
+}
+
+func TestDownloadFineTuneMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/files/file-1/content", r.URL.Path)
+		_, _ = io.WriteString(w, testFineTuneResults)
+	}))
+	defer server.Close()
+
+	metrics, err := DownloadFineTuneMetrics(context.Background(), New(Config{BaseURL: server.URL}).Files(), "file-1")
+	require.NoError(t, err)
+	assert.Len(t, metrics, 4)
+}
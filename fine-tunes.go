@@ -1,12 +1,27 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 )
 
-const fineTunesAPIEndpoint = "/fine-tunes"
+const (
+	fineTunesAPIEndpoint = "/fine-tunes"
+
+	fineTuneStatusSucceeded = "succeeded"
+	fineTuneStatusFailed    = "failed"
+	fineTuneStatusCancelled = "cancelled"
+
+	defaultFineTuneWaitPollInterval    = time.Second * 10
+	defaultFineTuneWaitMaxPollInterval = time.Minute
+	defaultFineTuneStreamReconnects    = 5
+	fineTuneEventLevelError            = "error"
+)
 
 // FineTune represents the information of a fine-tuning task.
 type FineTune struct {
@@ -42,6 +57,8 @@ type FineTune struct {
 // FineTuneEvent represents a single event that has taken
 // place during a fine-tuning task.
 type FineTuneEvent struct {
+	// ID is the unique identifier for the event.
+	ID string `json:"id"`
 	// CreatedAt is the timestamp when the event took place.
 	CreatedAt int `json:"created_at"`
 	// Level is the severity level of the event.
@@ -78,9 +95,9 @@ type FineTuneParams struct {
 	// BatchSize is the number of samples per training iteration.
 	BatchSize int `json:"batch_size,omitempty"`
 	// LearningRateMultiplier is the multiplier applied to the learning rate.
-	LearningRateMultiplier float64 `json:"learning_rate_multiplier,omitempty"`
+	LearningRateMultiplier *float64 `json:"learning_rate_multiplier,omitempty"`
 	// PromptLossWeight is the weight applied to the prompt loss.
-	PromptLossWeight float64 `json:"prompt_loss_weight,omitempty"`
+	PromptLossWeight *float64 `json:"prompt_loss_weight,omitempty"`
 	// ComputeClassificationMetrics indicates whether to compute
 	// classification metrics.
 	ComputeClassificationMetrics bool `json:"compute_classification_metrics,omitempty"`
@@ -97,20 +114,79 @@ type FineTuneParams struct {
 	Suffix string `json:"suffix,omitempty"`
 }
 
+// FineTuneWaitOptions holds the options of FineTunesAPI.WaitForCompletion.
+type FineTuneWaitOptions struct {
+	// PollInterval is the delay between the first polls
+	// of the fine-tune. Defaults to 10s.
+	PollInterval time.Duration
+	// Backoff is the factor the poll interval is multiplied by after
+	// every poll. Values lower than 1 keep the interval constant.
+	Backoff float64
+	// MaxPollInterval caps the poll interval. Defaults to 1m.
+	MaxPollInterval time.Duration
+	// OnStatus, if set, is called with the fine-tune
+	// whenever its status changes, and after the first poll.
+	OnStatus func(FineTune)
+	// OnEvent, if set, is called with every new event of the fine-tune.
+	OnEvent func(FineTuneEvent)
+}
+
+func (o FineTuneWaitOptions) withDefaults() FineTuneWaitOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultFineTuneWaitPollInterval
+	}
+
+	if o.MaxPollInterval <= 0 {
+		o.MaxPollInterval = defaultFineTuneWaitMaxPollInterval
+	}
+
+	if o.MaxPollInterval < o.PollInterval {
+		o.MaxPollInterval = o.PollInterval
+	}
+
+	return o
+}
+
 // FineTunesAPI represents the interface for managing
 // fine-tuning models in OpenAI GPT.
+//
+// Deprecated: FineTunesAPI targets the legacy /fine-tunes
+// endpoint. Use FineTuningJobsAPI instead.
 type FineTunesAPI interface {
 	// GetAll retrieves all the fine-tuning models available.
 	GetAll() ([]FineTune, error)
+	// GetAllWithContext is like GetAll but uses the given context.
+	GetAllWithContext(ctx context.Context) ([]FineTune, error)
+	// List returns a pager over the fine-tuning models,
+	// fetching their pages lazily.
+	List(ctx context.Context, params ListParams) *Pager[FineTune]
 	// GetByID retrieves a specific fine-tuning model based on its ID.
 	GetByID(id string) (FineTune, error)
+	// GetByIDWithContext is like GetByID but uses the given context.
+	GetByIDWithContext(ctx context.Context, id string) (FineTune, error)
 	// Create creates a new fine-tuning model.
 	Create(FineTuneParams) (FineTune, error)
+	// CreateWithContext is like Create but uses the given context.
+	CreateWithContext(context.Context, FineTuneParams) (FineTune, error)
 	// Cancel cancels a specific fine-tuning model based on its ID.
 	Cancel(id string) (FineTune, error)
+	// CancelWithContext is like Cancel but uses the given context.
+	CancelWithContext(ctx context.Context, id string) (FineTune, error)
 	// GetEvents retrieves all the events of a specific
 	// fine-tuning model based on its ID.
 	GetEvents(fineTuneID string) ([]FineTuneEvent, error)
+	// GetEventsWithContext is like GetEvents but uses the given context.
+	GetEventsWithContext(ctx context.Context, fineTuneID string) ([]FineTuneEvent, error)
+	// StreamEvents streams the events of a specific fine-tuning
+	// model as they happen, until the fine-tune is done.
+	StreamEvents(ctx context.Context, fineTuneID string) (*FineTuneEventStream, error)
+	// WaitForCompletion polls a specific fine-tuning model until it is
+	// done and returns it. It returns a *FineTuneError along with the
+	// fine-tune if it has failed or been cancelled.
+	WaitForCompletion(ctx context.Context, fineTuneID string, opts FineTuneWaitOptions) (FineTune, error)
+	// ListEvents returns a pager over the events of a specific
+	// fine-tuning model, fetching their pages lazily.
+	ListEvents(ctx context.Context, fineTuneID string, params ListParams) *Pager[FineTuneEvent]
 }
 
 type fineTunesAPI struct {
@@ -118,8 +194,12 @@ type fineTunesAPI struct {
 }
 
 func (api fineTunesAPI) GetAll() ([]FineTune, error) {
-	url := fmt.Sprintf("%s%s", baseURL, fineTunesAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetAllWithContext(context.Background())
+}
+
+func (api fineTunesAPI) GetAllWithContext(ctx context.Context) ([]FineTune, error) {
+	url := api.c.endpointURL(fineTunesAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -135,9 +215,19 @@ func (api fineTunesAPI) GetAll() ([]FineTune, error) {
 	return response.Data, nil
 }
 
+func (api fineTunesAPI) List(ctx context.Context, params ListParams) *Pager[FineTune] {
+	url := api.c.endpointURL(fineTunesAPIEndpoint)
+
+	return newPager(ctx, api.c, url, params.query(), func(f FineTune) string { return f.ID })
+}
+
 func (api fineTunesAPI) GetByID(id string) (FineTune, error) {
-	url := fmt.Sprintf("%s%s/%s", baseURL, fineTunesAPIEndpoint, id)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetByIDWithContext(context.Background(), id)
+}
+
+func (api fineTunesAPI) GetByIDWithContext(ctx context.Context, id string) (FineTune, error) {
+	url := api.c.endpointURL(fineTunesAPIEndpoint, id)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return FineTune{}, err
 	}
@@ -151,8 +241,12 @@ func (api fineTunesAPI) GetByID(id string) (FineTune, error) {
 }
 
 func (api fineTunesAPI) Create(params FineTuneParams) (FineTune, error) {
-	url := fmt.Sprintf("%s%s", baseURL, fineTunesAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api fineTunesAPI) CreateWithContext(ctx context.Context, params FineTuneParams) (FineTune, error) {
+	url := api.c.endpointURL(fineTunesAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return FineTune{}, err
 	}
@@ -166,8 +260,12 @@ func (api fineTunesAPI) Create(params FineTuneParams) (FineTune, error) {
 }
 
 func (api fineTunesAPI) Cancel(id string) (FineTune, error) {
-	url := fmt.Sprintf("%s%s/%s/cancel", baseURL, fineTunesAPIEndpoint, id)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, nil)
+	return api.CancelWithContext(context.Background(), id)
+}
+
+func (api fineTunesAPI) CancelWithContext(ctx context.Context, id string) (FineTune, error) {
+	url := api.c.endpointURL(fineTunesAPIEndpoint, id, "cancel")
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, nil)
 	if err != nil {
 		return FineTune{}, err
 	}
@@ -180,10 +278,13 @@ func (api fineTunesAPI) Cancel(id string) (FineTune, error) {
 	return response, nil
 }
 
-// TODO: support stream
 func (api fineTunesAPI) GetEvents(fineTuneID string) ([]FineTuneEvent, error) {
-	url := fmt.Sprintf("%s%s/%s/events", baseURL, fineTunesAPIEndpoint, fineTuneID)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetEventsWithContext(context.Background(), fineTuneID)
+}
+
+func (api fineTunesAPI) GetEventsWithContext(ctx context.Context, fineTuneID string) ([]FineTuneEvent, error) {
+	url := api.c.endpointURL(fineTunesAPIEndpoint, fineTuneID, "events")
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -198,3 +299,269 @@ func (api fineTunesAPI) GetEvents(fineTuneID string) ([]FineTuneEvent, error) {
 
 	return response.Data, nil
 }
+
+func (api fineTunesAPI) ListEvents(ctx context.Context, fineTuneID string, params ListParams) *Pager[FineTuneEvent] {
+	url := api.c.endpointURL(fineTunesAPIEndpoint, fineTuneID, "events")
+
+	return newPager(ctx, api.c, url, params.query(), func(e FineTuneEvent) string { return e.ID })
+}
+
+func (api fineTunesAPI) StreamEvents(ctx context.Context, fineTuneID string) (*FineTuneEventStream, error) {
+	s := &FineTuneEventStream{
+		ctx:  ctx,
+		api:  api,
+		id:   fineTuneID,
+		seen: map[string]struct{}{},
+	}
+
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// FineTuneEventStream is a stream of the events of a fine-tune. It
+// reconnects when the connection is lost or closed by the server
+// while the fine-tune is running, skipping the events already
+// received. The delay before reconnecting follows the backoff of
+// the client's RetryPolicy, growing with every reconnection until
+// a new event is received, and reconnections are reported to its
+// OnRetry callback. The stream fails with the last error once it
+// has reconnected RetryPolicy.MaxAttempts-1 times in a row without
+// receiving a new event, or 5 times when MaxAttempts is lower than
+// 2. It must be closed when no longer needed.
+type FineTuneEventStream struct {
+	ctx  context.Context
+	api  fineTunesAPI
+	id   string
+	r    *streamReader[FineTuneEvent]
+	seen map[string]struct{}
+	// failures is the number of reconnections since the last new
+	// event and err is the error that ended the last connection.
+	failures int
+	err      error
+	// draining is whether the fine-tune is done and the
+	// stream is read for the last time.
+	draining bool
+	done     bool
+}
+
+// Recv returns the next event in the stream. It returns io.EOF once
+// the fine-tune has reached a terminal status and all its events
+// have been received. An event that cannot be decoded is returned
+// as an error without ending the stream, which goes on with the
+// next event.
+func (s *FineTuneEventStream) Recv() (FineTuneEvent, error) {
+	for {
+		if s.done {
+			return FineTuneEvent{}, io.EOF
+		}
+
+		if s.r == nil {
+			if err := s.reconnect(); err != nil {
+				return FineTuneEvent{}, err
+			}
+
+			continue
+		}
+
+		event, err := s.r.recv()
+		if err == nil {
+			key := fineTuneEventKey(event)
+
+			if _, ok := s.seen[key]; ok {
+				continue
+			}
+
+			s.seen[key] = struct{}{}
+			s.failures = 0
+
+			return event, nil
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) || s.ctx.Err() != nil {
+			s.Close()
+
+			return FineTuneEvent{}, err
+		}
+
+		if !isTransientError(err) {
+			return FineTuneEvent{}, err
+		}
+
+		_ = s.r.close()
+		s.r = nil
+		s.err = err
+
+		if s.draining {
+			s.done = true
+
+			continue
+		}
+
+		fineTune, statusErr := s.api.GetByIDWithContext(s.ctx, s.id)
+		if statusErr != nil {
+			return FineTuneEvent{}, statusErr
+		}
+
+		if isFineTuneDone(fineTune.Status) {
+			// a stream cut off before its end is read once
+			// more to receive the last events of the fine-tune
+			s.done = err == io.EOF
+			s.draining = true
+		}
+	}
+}
+
+// Close closes the underlying connection of the stream.
+func (s *FineTuneEventStream) Close() error {
+	s.done = true
+	if s.r == nil {
+		return nil
+	}
+
+	err := s.r.close()
+	s.r = nil
+
+	return err
+}
+
+// reconnect connects to the event feed again after a delay growing
+// with the consecutive reconnections, or returns the error that ended
+// the last connection once there have been too many of them.
+func (s *FineTuneEventStream) reconnect() error {
+	policy := s.api.c.cfg.RetryPolicy.withDefaults()
+
+	maxReconnects := policy.MaxAttempts - 1
+	if maxReconnects < 1 {
+		maxReconnects = defaultFineTuneStreamReconnects
+	}
+
+	if s.failures >= maxReconnects {
+		s.done = true
+
+		return s.err
+	}
+
+	s.failures++
+
+	delay := policy.backoff(s.failures)
+	if policy.OnRetry != nil {
+		policy.OnRetry(RetryEvent{Attempt: s.failures, Delay: delay, Err: s.err})
+	}
+
+	timer := time.NewTimer(delay)
+	select {
+	case <-s.ctx.Done():
+		timer.Stop()
+
+		return requestError(s.ctx, s.ctx.Err())
+	case <-timer.C:
+	}
+
+	return s.connect()
+}
+
+func (s *FineTuneEventStream) connect() error {
+	url := s.api.c.endpointURL(fineTunesAPIEndpoint, s.id, "events") + "?stream=true"
+
+	r, err := getStreamResponse[FineTuneEvent](s.ctx, s.api.c, url, http.MethodGet, nil)
+	if err != nil {
+		s.err = err
+
+		return err
+	}
+
+	s.r = r
+
+	return nil
+}
+
+func (api fineTunesAPI) WaitForCompletion(ctx context.Context, fineTuneID string, opts FineTuneWaitOptions) (FineTune, error) {
+	opts = opts.withDefaults()
+	interval := opts.PollInterval
+	seen := map[string]struct{}{}
+	status := ""
+
+	for {
+		fineTune, err := api.GetByIDWithContext(ctx, fineTuneID)
+		if err != nil {
+			return FineTune{}, err
+		}
+
+		if opts.OnEvent != nil {
+			for _, event := range fineTune.Events {
+				key := fineTuneEventKey(event)
+				if _, ok := seen[key]; !ok {
+					seen[key] = struct{}{}
+					opts.OnEvent(event)
+				}
+			}
+		}
+
+		if fineTune.Status != status {
+			status = fineTune.Status
+			if opts.OnStatus != nil {
+				opts.OnStatus(fineTune)
+			}
+		}
+
+		switch fineTune.Status {
+		case fineTuneStatusSucceeded:
+			return fineTune, nil
+		case fineTuneStatusFailed, fineTuneStatusCancelled:
+			return fineTune, &FineTuneError{
+				FineTune: fineTune,
+				Status:   fineTune.Status,
+				Message:  lastFineTuneErrorMessage(fineTune.Events),
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return FineTune{}, requestError(ctx, ctx.Err())
+		case <-timer.C:
+		}
+
+		if opts.Backoff > 1 {
+			interval = time.Duration(float64(interval) * opts.Backoff)
+			if interval > opts.MaxPollInterval {
+				interval = opts.MaxPollInterval
+			}
+		}
+	}
+}
+
+// fineTuneEventKey returns the key identifying an event, which
+// is its ID or, for events without one, its time and message.
+func fineTuneEventKey(event FineTuneEvent) string {
+	if event.ID != "" {
+		return event.ID
+	}
+
+	return fmt.Sprintf("%d:%s", event.CreatedAt, event.Message)
+}
+
+func lastFineTuneErrorMessage(events []FineTuneEvent) string {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Level == fineTuneEventLevelError {
+			return events[i].Message
+		}
+	}
+
+	return ""
+}
+
+func isFineTuneDone(status string) bool {
+	switch status {
+	case fineTuneStatusSucceeded, fineTuneStatusFailed, fineTuneStatusCancelled:
+		return true
+	}
+
+	return false
+}
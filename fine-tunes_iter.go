@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package gopenai
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the remaining events of the stream,
+// along with the error that stopped the iteration, if any, as the last
+// pair. The stream is closed once the iteration is over.
+func (s *FineTuneEventStream) All() iter.Seq2[FineTuneEvent, error] {
+	return func(yield func(FineTuneEvent, error) bool) {
+		defer s.Close()
+
+		for {
+			event, err := s.Recv()
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(event, err) || err != nil {
+				return
+			}
+		}
+	}
+}
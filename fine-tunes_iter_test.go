@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package gopenai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFineTuneEventStreamAll(t *testing.T) {
+	server := newTestFineTuneEventsServer(t, []string{
+		"data: {\"created_at\": 1, \"message\": \"created\"}\n\n" +
+			"data: {\"created_at\": 2, \"message\": \"succeeded\"}\n\n" +
+			"data: [DONE]\n\n",
+	}, []string{"succeeded"})
+
+	stream, err := New(Config{BaseURL: server.URL}).FineTunes().StreamEvents(context.Background(), "ft-1")
+	require.NoError(t, err)
+
+	var messages []string
+	for event, err := range stream.All() {
+		require.NoError(t, err)
+		messages = append(messages, event.Message)
+	}
+
+	assert.Equal(t, []string{"created", "succeeded"}, messages)
+}
@@ -0,0 +1,366 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFineTuneEventsServer serves the given event streams, one per
+// connection, and the given fine-tune statuses, one per status request.
+// A stream without a "data: [DONE]" line is cut off.
+func newTestFineTuneEventsServer(t *testing.T, streams, statuses []string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !strings.HasSuffix(r.URL.Path, "/events") {
+			require.NotEmpty(t, statuses)
+			w.Header().Set(headerNameContentType, contentTypeJSON)
+			_, _ = io.WriteString(w, `{"id": "ft-1", "status": "`+statuses[0]+`"}`)
+			statuses = statuses[1:]
+
+			return
+		}
+
+		assert.Equal(t, "true", r.URL.Query().Get("stream"))
+		require.NotEmpty(t, streams)
+
+		stream := streams[0]
+		streams = streams[1:]
+
+		w.Header().Set(headerNameContentType, "text/event-stream")
+		_, _ = io.WriteString(w, stream)
+		w.(http.Flusher).Flush()
+
+		if !strings.Contains(stream, streamDoneMessage) {
+			panic(http.ErrAbortHandler)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func recvFineTuneEvents(t *testing.T, stream *FineTuneEventStream) ([]string, error) {
+	t.Helper()
+
+	var messages []string
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+
+			return messages, err
+		}
+
+		messages = append(messages, event.Message)
+	}
+}
+
+func TestFineTunesStreamEvents(t *testing.T) {
+	testCases := []struct {
+		name             string
+		streams          []string
+		statuses         []string
+		expectedMessages []string
+	}{
+		{
+			name: "reconnects and skips seen events",
+			streams: []string{
+				"data: {\"created_at\": 1, \"message\": \"created\"}\n\n" +
+					"data: {\"created_at\": 2, \"message\": \"started\"}\n\n",
+				"data: {\"created_at\": 1, \"message\": \"created\"}\n\n" +
+					"data: {\"created_at\": 2, \"message\": \"started\"}\n\n" +
+					"data: {\"created_at\": 3, \"message\": \"succeeded\"}\n\n" +
+					"data: [DONE]\n\n",
+			},
+			statuses:         []string{"running", "succeeded"},
+			expectedMessages: []string{"created", "started", "succeeded"},
+		},
+		{
+			name: "reads a cut off stream once more when done",
+			streams: []string{
+				"data: {\"id\": \"e1\", \"created_at\": 1, \"message\": \"created\"}\n\n",
+				"data: {\"id\": \"e1\", \"created_at\": 1, \"message\": \"created\"}\n\n" +
+					"data: {\"id\": \"e2\", \"created_at\": 1, \"message\": \"failed\"}\n\n" +
+					"data: [DONE]\n\n",
+			},
+			statuses:         []string{"failed"},
+			expectedMessages: []string{"created", "failed"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestFineTuneEventsServer(t, tc.streams, tc.statuses)
+			client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{BaseBackoff: time.Millisecond}})
+
+			stream, err := client.FineTunes().StreamEvents(context.Background(), "ft-1")
+			require.NoError(t, err)
+			defer stream.Close()
+
+			messages, err := recvFineTuneEvents(t, stream)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMessages, messages)
+		})
+	}
+}
+
+func TestFineTunesStreamEventsReconnectBackoff(t *testing.T) {
+	server := newTestFineTuneEventsServer(t, []string{
+		"",
+		"",
+		"",
+		"data: {\"id\": \"e1\", \"created_at\": 1, \"message\": \"created\"}\n\n",
+		"data: {\"id\": \"e2\", \"created_at\": 2, \"message\": \"succeeded\"}\n\ndata: [DONE]\n\n",
+	}, []string{"running", "running", "running", "running", "succeeded"})
+
+	var delays []time.Duration
+
+	client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{
+		BaseBackoff: time.Millisecond,
+		OnRetry: func(event RetryEvent) {
+			assert.Error(t, event.Err)
+			delays = append(delays, event.Delay)
+		},
+	}})
+
+	stream, err := client.FineTunes().StreamEvents(context.Background(), "ft-1")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	messages, err := recvFineTuneEvents(t, stream)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"created", "succeeded"}, messages)
+	assert.Equal(t, []time.Duration{
+		time.Millisecond,
+		time.Millisecond * 2,
+		time.Millisecond * 4,
+		time.Millisecond,
+	}, delays)
+}
+
+func TestFineTunesStreamEventsMaxReconnects(t *testing.T) {
+	server := newTestFineTuneEventsServer(t, []string{
+		"data: {\"id\": \"e1\", \"created_at\": 1, \"message\": \"created\"}\n\n",
+		"",
+		"",
+	}, []string{"running", "running", "running"})
+
+	var reconnections int
+
+	client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		OnRetry:     func(RetryEvent) { reconnections++ },
+	}})
+
+	stream, err := client.FineTunes().StreamEvents(context.Background(), "ft-1")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	messages, err := recvFineTuneEvents(t, stream)
+	assert.True(t, isTransientError(err), "unexpected error: %v", err)
+	assert.Equal(t, []string{"created"}, messages)
+	assert.Equal(t, 2, reconnections)
+
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFineTunesStreamEventsMalformedEvent(t *testing.T) {
+	server := newTestFineTuneEventsServer(t, []string{
+		"data: {\"id\": \"e1\", \"created_at\": 1, \"message\": \"created\"}\n\n" +
+			"data: {not json\n\n" +
+			"data: {\"id\": \"e2\", \"created_at\": 2, \"message\": \"succeeded\"}\n\n" +
+			"data: [DONE]\n\n",
+	}, []string{"succeeded"})
+
+	client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{
+		OnRetry: func(event RetryEvent) {
+			t.Errorf("unexpected reconnection: %v", event.Err)
+		},
+	}})
+
+	stream, err := client.FineTunes().StreamEvents(context.Background(), "ft-1")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "created", event.Message)
+
+	_, err = stream.Recv()
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	messages, err := recvFineTuneEvents(t, stream)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"succeeded"}, messages)
+}
+
+func TestFineTunesStreamEventsOutlastsRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/events") {
+			_, _ = io.WriteString(w, `{"id": "ft-1", "status": "succeeded"}`)
+
+			return
+		}
+
+		w.Header().Set(headerNameContentType, "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"id\": \"e1\", \"created_at\": 1, \"message\": \"created\"}\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(time.Millisecond * 200)
+		_, _ = io.WriteString(w, "data: {\"id\": \"e2\", \"created_at\": 2, \"message\": \"succeeded\"}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := New(Config{BaseURL: server.URL, RequestTimeout: time.Millisecond * 50, RetryPolicy: RetryPolicy{
+		OnRetry: func(event RetryEvent) {
+			t.Errorf("unexpected reconnection: %v", event.Err)
+		},
+	}})
+
+	stream, err := client.FineTunes().StreamEvents(context.Background(), "ft-1")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	messages, err := recvFineTuneEvents(t, stream)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"created", "succeeded"}, messages)
+}
+
+func TestFineTunesStreamEventsErrorEvent(t *testing.T) {
+	server := newTestFineTuneEventsServer(t, []string{
+		"data: {\"created_at\": 1, \"message\": \"created\"}\n\n" +
+			"event: error\ndata: {\"error\": {\"message\": \"overloaded\"}}\n\n" +
+			"data: [DONE]\n\n",
+	}, nil)
+
+	stream, err := New(Config{BaseURL: server.URL}).FineTunes().StreamEvents(context.Background(), "ft-1")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	messages, err := recvFineTuneEvents(t, stream)
+	assert.Equal(t, []string{"created"}, messages)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "overloaded", apiErr.Message)
+}
+
+func TestFineTunesWaitForCompletion(t *testing.T) {
+	testCases := []struct {
+		name             string
+		responses        []string
+		expectedStatuses []string
+		expectedMessages []string
+		expectedModel    string
+		expectedErr      error
+		expectedErrMsg   string
+	}{
+		{
+			name: "succeeded",
+			responses: []string{
+				`{"id": "ft-1", "status": "pending", "events": [{"created_at": 1, "message": "created"}]}`,
+				`{"id": "ft-1", "status": "running", "events": [{"created_at": 1, "message": "created"}, {"created_at": 2, "message": "started"}]}`,
+				`{"id": "ft-1", "status": "running", "events": [{"created_at": 1, "message": "created"}, {"created_at": 2, "message": "started"}]}`,
+				`{"id": "ft-1", "status": "succeeded", "fine_tuned_model": "curie:ft-1", "events": [{"created_at": 3, "message": "done"}]}`,
+			},
+			expectedStatuses: []string{"pending", "running", "succeeded"},
+			expectedMessages: []string{"created", "started", "done"},
+			expectedModel:    "curie:ft-1",
+		},
+		{
+			name: "failed",
+			responses: []string{
+				`{"id": "ft-1", "status": "failed", "events": [{"created_at": 1, "level": "error", "message": "invalid file"}, {"created_at": 2, "message": "stopped"}]}`,
+			},
+			expectedStatuses: []string{"failed"},
+			expectedMessages: []string{"invalid file", "stopped"},
+			expectedErr:      ErrFineTuneFailed,
+			expectedErrMsg:   "invalid file",
+		},
+		{
+			name: "cancelled",
+			responses: []string{
+				`{"id": "ft-1", "status": "cancelled"}`,
+			},
+			expectedStatuses: []string{"cancelled"},
+			expectedErr:      ErrFineTuneCancelled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			responses := tc.responses
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NotEmpty(t, responses)
+				w.Header().Set(headerNameContentType, contentTypeJSON)
+				_, _ = io.WriteString(w, responses[0])
+				responses = responses[1:]
+			}))
+			defer server.Close()
+
+			var (
+				statuses []string
+				messages []string
+			)
+
+			fineTune, err := New(Config{BaseURL: server.URL}).FineTunes().WaitForCompletion(context.Background(), "ft-1", FineTuneWaitOptions{
+				PollInterval: time.Millisecond,
+				Backoff:      2,
+				OnStatus:     func(f FineTune) { statuses = append(statuses, f.Status) },
+				OnEvent:      func(e FineTuneEvent) { messages = append(messages, e.Message) },
+			})
+
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			assert.Equal(t, tc.expectedMessages, messages)
+			assert.Empty(t, responses)
+
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedModel, fineTune.FineTunedModel)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+
+			var fineTuneErr *FineTuneError
+			require.ErrorAs(t, err, &fineTuneErr)
+			assert.Equal(t, tc.expectedErrMsg, fineTuneErr.Message)
+			assert.Equal(t, "ft-1", fineTuneErr.FineTune.ID)
+		})
+	}
+}
+
+func TestFineTunesWaitForCompletionCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, `{"id": "ft-1", "status": "running"}`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	_, err := New(Config{BaseURL: server.URL}).FineTunes().WaitForCompletion(ctx, "ft-1", FineTuneWaitOptions{PollInterval: time.Millisecond})
+	assert.ErrorIs(t, err, ErrRequestDeadlineExceeded)
+}
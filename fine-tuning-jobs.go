@@ -0,0 +1,405 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	fineTuningJobsAPIEndpoint = "/fine_tuning/jobs"
+
+	hyperparameterAuto = "auto"
+)
+
+// FineTuningJobStatus is an enum type representing
+// the status of a fine-tuning job.
+type FineTuningJobStatus string
+
+// FineTuningJobStatus enum values
+const (
+	FineTuningJobStatusValidatingFiles FineTuningJobStatus = "validating_files"
+	FineTuningJobStatusQueued          FineTuningJobStatus = "queued"
+	FineTuningJobStatusRunning         FineTuningJobStatus = "running"
+	FineTuningJobStatusPaused          FineTuningJobStatus = "paused"
+	FineTuningJobStatusSucceeded       FineTuningJobStatus = "succeeded"
+	FineTuningJobStatusFailed          FineTuningJobStatus = "failed"
+	FineTuningJobStatusCancelled       FineTuningJobStatus = "cancelled"
+)
+
+// FineTuningMethodType is an enum type representing
+// the method used to fine-tune a model.
+type FineTuningMethodType string
+
+// FineTuningMethodType enum values
+const (
+	FineTuningMethodTypeSupervised FineTuningMethodType = "supervised"
+	FineTuningMethodTypeDPO        FineTuningMethodType = "dpo"
+)
+
+// FineTuningJob represents a job fine-tuning a model.
+type FineTuningJob struct {
+	// ID is the identifier of the job.
+	ID string `json:"id"`
+	// Object is the object type, which is always "fine_tuning.job".
+	Object string `json:"object"`
+	// CreatedAt is the UNIX timestamp of when the job was created.
+	CreatedAt int `json:"created_at"`
+	// FinishedAt is the UNIX timestamp of when the job
+	// finished, or 0 if it is still running.
+	FinishedAt int `json:"finished_at"`
+	// EstimatedFinish is the UNIX timestamp of when the job is
+	// estimated to finish, or 0 if it is not running.
+	EstimatedFinish int `json:"estimated_finish"`
+	// Model is the base model being fine-tuned.
+	Model string `json:"model"`
+	// FineTunedModel is the name of the fine-tuned model,
+	// once the job has succeeded.
+	FineTunedModel string `json:"fine_tuned_model"`
+	// OrganizationID is the organization that owns the job.
+	OrganizationID string `json:"organization_id"`
+	// Status is the status of the job.
+	Status FineTuningJobStatus `json:"status"`
+	// Hyperparameters are the hyperparameters used by the job.
+	Hyperparameters FineTuningHyperparameters `json:"hyperparameters"`
+	// Method is the method used to fine-tune the model.
+	Method *FineTuningMethod `json:"method,omitempty"`
+	// TrainingFile is the ID of the training file.
+	TrainingFile string `json:"training_file"`
+	// ValidationFile is the ID of the validation file.
+	ValidationFile string `json:"validation_file"`
+	// ResultFiles are the IDs of the result files.
+	ResultFiles []string `json:"result_files"`
+	// TrainedTokens is the number of billable tokens
+	// processed by the job, once it has succeeded.
+	TrainedTokens int `json:"trained_tokens"`
+	// Error describes why the job failed, if it did.
+	Error *FineTuningJobError `json:"error"`
+	// Integrations are the integrations enabled for the job.
+	Integrations []FineTuningIntegration `json:"integrations"`
+	// Seed is the seed used by the job.
+	Seed int `json:"seed"`
+	// Metadata is the metadata attached to the job.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// FineTuningJobError describes why a fine-tuning job failed.
+type FineTuningJobError struct {
+	// Code is the machine readable error code.
+	Code string `json:"code"`
+	// Message is the human readable error message.
+	Message string `json:"message"`
+	// Param is the parameter that was invalid, if any.
+	Param string `json:"param"`
+}
+
+// FineTuningHyperparameter is the value of a hyperparameter, which
+// is either a number or chosen automatically by the API.
+type FineTuningHyperparameter struct {
+	// Auto is whether the value is chosen by the API.
+	Auto bool
+	// Value is the value of the hyperparameter, when not Auto.
+	Value float64
+}
+
+// AutoHyperparameter returns a hyperparameter chosen by the API.
+func AutoHyperparameter() *FineTuningHyperparameter {
+	return &FineTuningHyperparameter{Auto: true}
+}
+
+// HyperparameterValue returns a hyperparameter with the given value.
+func HyperparameterValue(v float64) *FineTuningHyperparameter {
+	return &FineTuningHyperparameter{Value: v}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h FineTuningHyperparameter) MarshalJSON() ([]byte, error) {
+	if h.Auto {
+		return json.Marshal(hyperparameterAuto)
+	}
+
+	return json.Marshal(h.Value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *FineTuningHyperparameter) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != hyperparameterAuto {
+			return fmt.Errorf("invalid hyperparameter value %q", s)
+		}
+
+		*h = FineTuningHyperparameter{Auto: true}
+
+		return nil
+	}
+
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*h = FineTuningHyperparameter{Value: v}
+
+	return nil
+}
+
+// FineTuningHyperparameters represents the hyperparameters of a
+// fine-tuning job. Unset hyperparameters are chosen by the API.
+type FineTuningHyperparameters struct {
+	// BatchSize is the number of examples in each batch.
+	BatchSize *FineTuningHyperparameter `json:"batch_size,omitempty"`
+	// LearningRateMultiplier is the multiplier applied to the learning rate.
+	LearningRateMultiplier *FineTuningHyperparameter `json:"learning_rate_multiplier,omitempty"`
+	// NEpochs is the number of epochs to train the model for.
+	NEpochs *FineTuningHyperparameter `json:"n_epochs,omitempty"`
+	// Beta is the weight of the penalty between the policy and
+	// the reference model. It only applies to the DPO method.
+	Beta *FineTuningHyperparameter `json:"beta,omitempty"`
+}
+
+// FineTuningMethod represents the method used to fine-tune a model.
+type FineTuningMethod struct {
+	// Type is the type of the method.
+	Type FineTuningMethodType `json:"type"`
+	// Supervised holds the configuration of the supervised method.
+	Supervised *FineTuningMethodConfig `json:"supervised,omitempty"`
+	// DPO holds the configuration of the DPO method.
+	DPO *FineTuningMethodConfig `json:"dpo,omitempty"`
+}
+
+// FineTuningMethodConfig represents the configuration of a fine-tuning method.
+type FineTuningMethodConfig struct {
+	// Hyperparameters are the hyperparameters used by the method.
+	Hyperparameters FineTuningHyperparameters `json:"hyperparameters"`
+}
+
+// FineTuningIntegration represents an integration enabled for a fine-tuning job.
+type FineTuningIntegration struct {
+	// Type is the type of the integration, such as "wandb".
+	Type string `json:"type"`
+	// Wandb holds the configuration of the Weights and Biases integration.
+	Wandb *FineTuningWandbIntegration `json:"wandb,omitempty"`
+}
+
+// FineTuningWandbIntegration represents the configuration
+// of the Weights and Biases integration.
+type FineTuningWandbIntegration struct {
+	// Project is the project the run is created in.
+	Project string `json:"project"`
+	// Name is the display name of the run.
+	Name string `json:"name,omitempty"`
+	// Entity is the team or user the run is created for.
+	Entity string `json:"entity,omitempty"`
+	// Tags are the tags attached to the run.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// FineTuningJobParams represents the parameters for creating a fine-tuning job.
+type FineTuningJobParams struct {
+	// Model is the model to fine-tune.
+	Model string `json:"model"`
+	// TrainingFile is the ID of the training file.
+	TrainingFile string `json:"training_file"`
+	// ValidationFile is the ID of the validation file.
+	ValidationFile string `json:"validation_file,omitempty"`
+	// Suffix is appended to the name of the fine-tuned model.
+	Suffix string `json:"suffix,omitempty"`
+	// Hyperparameters are the hyperparameters of the job. Prefer
+	// the hyperparameters of Method, which replace them.
+	Hyperparameters *FineTuningHyperparameters `json:"hyperparameters,omitempty"`
+	// Method is the method used to fine-tune the model.
+	Method *FineTuningMethod `json:"method,omitempty"`
+	// Integrations are the integrations to enable for the job.
+	Integrations []FineTuningIntegration `json:"integrations,omitempty"`
+	// Seed makes the job reproducible.
+	Seed *int `json:"seed,omitempty"`
+	// Metadata is the metadata attached to the job.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// FineTuningJobListParams holds the parameters for listing fine-tuning jobs.
+type FineTuningJobListParams struct {
+	ListParams
+	// Metadata filters the jobs by their metadata.
+	Metadata map[string]string
+}
+
+func (p FineTuningJobListParams) query() url.Values {
+	query := p.ListParams.query()
+	for k, v := range p.Metadata {
+		query.Set(fmt.Sprintf("metadata[%s]", k), v)
+	}
+
+	return query
+}
+
+// FineTuningJobEvent represents an event of a fine-tuning job.
+type FineTuningJobEvent struct {
+	// ID is the identifier of the event.
+	ID string `json:"id"`
+	// Object is the object type, which is always "fine_tuning.job.event".
+	Object string `json:"object"`
+	// CreatedAt is the UNIX timestamp of when the event took place.
+	CreatedAt int `json:"created_at"`
+	// Level is the severity level of the event.
+	Level string `json:"level"`
+	// Message is the description of the event.
+	Message string `json:"message"`
+	// Type is the type of the event, such as "message" or "metrics".
+	Type string `json:"type"`
+	// Data holds the data of the event, such as training metrics.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// FineTuningJobCheckpoint represents a checkpoint
+// of the model created during a fine-tuning job.
+type FineTuningJobCheckpoint struct {
+	// ID is the identifier of the checkpoint.
+	ID string `json:"id"`
+	// Object is the object type, which is always "fine_tuning.job.checkpoint".
+	Object string `json:"object"`
+	// CreatedAt is the UNIX timestamp of when the checkpoint was created.
+	CreatedAt int `json:"created_at"`
+	// FineTunedModelCheckpoint is the name of the checkpoint model.
+	FineTunedModelCheckpoint string `json:"fine_tuned_model_checkpoint"`
+	// FineTuningJobID is the identifier of the job.
+	FineTuningJobID string `json:"fine_tuning_job_id"`
+	// StepNumber is the step the checkpoint was created at.
+	StepNumber int `json:"step_number"`
+	// Metrics are the metrics at the step of the checkpoint.
+	Metrics FineTuningCheckpointMetrics `json:"metrics"`
+}
+
+// FineTuningCheckpointMetrics represents the metrics at a checkpoint.
+type FineTuningCheckpointMetrics struct {
+	Step                       float64 `json:"step"`
+	TrainLoss                  float64 `json:"train_loss"`
+	TrainMeanTokenAccuracy     float64 `json:"train_mean_token_accuracy"`
+	ValidLoss                  float64 `json:"valid_loss"`
+	ValidMeanTokenAccuracy     float64 `json:"valid_mean_token_accuracy"`
+	FullValidLoss              float64 `json:"full_valid_loss"`
+	FullValidMeanTokenAccuracy float64 `json:"full_valid_mean_token_accuracy"`
+}
+
+// FineTuningJobsAPI is the interface for managing fine-tuning jobs.
+// It replaces the deprecated FineTunesAPI.
+type FineTuningJobsAPI interface {
+	// Create creates a fine-tuning job.
+	Create(FineTuningJobParams) (FineTuningJob, error)
+	// CreateWithContext is like Create but uses the given context.
+	CreateWithContext(context.Context, FineTuningJobParams) (FineTuningJob, error)
+	// List returns a pager over the fine-tuning jobs,
+	// fetching their pages lazily.
+	List(ctx context.Context, params FineTuningJobListParams) *Pager[FineTuningJob]
+	// GetByID retrieves a fine-tuning job based on its ID.
+	GetByID(id string) (FineTuningJob, error)
+	// GetByIDWithContext is like GetByID but uses the given context.
+	GetByIDWithContext(ctx context.Context, id string) (FineTuningJob, error)
+	// Cancel cancels a fine-tuning job.
+	Cancel(id string) (FineTuningJob, error)
+	// CancelWithContext is like Cancel but uses the given context.
+	CancelWithContext(ctx context.Context, id string) (FineTuningJob, error)
+	// Pause pauses a running fine-tuning job.
+	Pause(id string) (FineTuningJob, error)
+	// PauseWithContext is like Pause but uses the given context.
+	PauseWithContext(ctx context.Context, id string) (FineTuningJob, error)
+	// Resume resumes a paused fine-tuning job.
+	Resume(id string) (FineTuningJob, error)
+	// ResumeWithContext is like Resume but uses the given context.
+	ResumeWithContext(ctx context.Context, id string) (FineTuningJob, error)
+	// ListEvents returns a pager over the events of
+	// a fine-tuning job, fetching their pages lazily.
+	ListEvents(ctx context.Context, jobID string, params ListParams) *Pager[FineTuningJobEvent]
+	// ListCheckpoints returns a pager over the checkpoints of
+	// a fine-tuning job, fetching their pages lazily.
+	ListCheckpoints(ctx context.Context, jobID string, params ListParams) *Pager[FineTuningJobCheckpoint]
+}
+
+type fineTuningJobsAPI struct {
+	c client
+}
+
+func (api fineTuningJobsAPI) Create(params FineTuningJobParams) (FineTuningJob, error) {
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api fineTuningJobsAPI) CreateWithContext(ctx context.Context, params FineTuningJobParams) (FineTuningJob, error) {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint)
+
+	return api.job(ctx, url, http.MethodPost, params)
+}
+
+func (api fineTuningJobsAPI) List(ctx context.Context, params FineTuningJobListParams) *Pager[FineTuningJob] {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint)
+
+	return newPager(ctx, api.c, url, params.query(), func(j FineTuningJob) string { return j.ID })
+}
+
+func (api fineTuningJobsAPI) GetByID(id string) (FineTuningJob, error) {
+	return api.GetByIDWithContext(context.Background(), id)
+}
+
+func (api fineTuningJobsAPI) GetByIDWithContext(ctx context.Context, id string) (FineTuningJob, error) {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint, id)
+
+	return api.job(ctx, url, http.MethodGet, nil)
+}
+
+func (api fineTuningJobsAPI) Cancel(id string) (FineTuningJob, error) {
+	return api.CancelWithContext(context.Background(), id)
+}
+
+func (api fineTuningJobsAPI) CancelWithContext(ctx context.Context, id string) (FineTuningJob, error) {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint, id, "cancel")
+
+	return api.job(ctx, url, http.MethodPost, nil)
+}
+
+func (api fineTuningJobsAPI) Pause(id string) (FineTuningJob, error) {
+	return api.PauseWithContext(context.Background(), id)
+}
+
+func (api fineTuningJobsAPI) PauseWithContext(ctx context.Context, id string) (FineTuningJob, error) {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint, id, "pause")
+
+	return api.job(ctx, url, http.MethodPost, nil)
+}
+
+func (api fineTuningJobsAPI) Resume(id string) (FineTuningJob, error) {
+	return api.ResumeWithContext(context.Background(), id)
+}
+
+func (api fineTuningJobsAPI) ResumeWithContext(ctx context.Context, id string) (FineTuningJob, error) {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint, id, "resume")
+
+	return api.job(ctx, url, http.MethodPost, nil)
+}
+
+func (api fineTuningJobsAPI) ListEvents(ctx context.Context, jobID string, params ListParams) *Pager[FineTuningJobEvent] {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint, jobID, "events")
+
+	return newPager(ctx, api.c, url, params.query(), func(e FineTuningJobEvent) string { return e.ID })
+}
+
+func (api fineTuningJobsAPI) ListCheckpoints(ctx context.Context, jobID string, params ListParams) *Pager[FineTuningJobCheckpoint] {
+	url := api.c.endpointURL(fineTuningJobsAPIEndpoint, jobID, "checkpoints")
+
+	return newPager(ctx, api.c, url, params.query(), func(c FineTuningJobCheckpoint) string { return c.ID })
+}
+
+func (api fineTuningJobsAPI) job(ctx context.Context, url, method string, params interface{}) (FineTuningJob, error) {
+	r, err := api.c.getJSONRequestResponse(ctx, url, method, params)
+	if err != nil {
+		return FineTuningJob{}, err
+	}
+
+	var response FineTuningJob
+	if err := json.Unmarshal(r, &response); err != nil {
+		return FineTuningJob{}, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,124 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFineTuningHyperparameterJSON(t *testing.T) {
+	var hyperparameters FineTuningHyperparameters
+	require.NoError(t, json.Unmarshal([]byte(`{"batch_size": "auto", "n_epochs": 3}`), &hyperparameters))
+
+	assert.Equal(t, FineTuningHyperparameters{
+		BatchSize: AutoHyperparameter(),
+		NEpochs:   HyperparameterValue(3),
+	}, hyperparameters)
+
+	data, err := json.Marshal(hyperparameters)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"batch_size": "auto", "n_epochs": 3}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"batch_size": "max"}`), &hyperparameters))
+}
+
+func TestFineTuningJobsCreate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/fine_tuning/jobs", r.URL.Path)
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"model": "gpt-4o-mini",
+			"training_file": "file-1",
+			"method": {"type": "dpo", "dpo": {"hyperparameters": {"beta": "auto", "n_epochs": 2}}},
+			"integrations": [{"type": "wandb", "wandb": {"project": "evals"}}],
+			"seed": 42
+		}`, string(body))
+
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, `{
+			"id": "ftjob-1",
+			"object": "fine_tuning.job",
+			"status": "failed",
+			"estimated_finish": 1700000000,
+			"hyperparameters": {"batch_size": "auto", "learning_rate_multiplier": 1.8, "n_epochs": 2},
+			"method": {"type": "dpo", "dpo": {"hyperparameters": {"beta": 0.1}}},
+			"error": {"code": "invalid_training_file", "message": "bad file", "param": "training_file"},
+			"result_files": [],
+			"seed": 42
+		}`)
+	}))
+	defer server.Close()
+
+	job, err := New(Config{BaseURL: server.URL}).FineTuningJobs().Create(FineTuningJobParams{
+		Model:        "gpt-4o-mini",
+		TrainingFile: "file-1",
+		Method: &FineTuningMethod{
+			Type: FineTuningMethodTypeDPO,
+			DPO: &FineTuningMethodConfig{Hyperparameters: FineTuningHyperparameters{
+				Beta:    AutoHyperparameter(),
+				NEpochs: HyperparameterValue(2),
+			}},
+		},
+		Integrations: []FineTuningIntegration{{Type: "wandb", Wandb: &FineTuningWandbIntegration{Project: "evals"}}},
+		Seed:         Int(42),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, FineTuningJobStatusFailed, job.Status)
+	assert.Equal(t, 1700000000, job.EstimatedFinish)
+	assert.Equal(t, AutoHyperparameter(), job.Hyperparameters.BatchSize)
+	assert.Equal(t, HyperparameterValue(1.8), job.Hyperparameters.LearningRateMultiplier)
+	assert.Equal(t, HyperparameterValue(0.1), job.Method.DPO.Hyperparameters.Beta)
+	assert.Equal(t, &FineTuningJobError{Code: "invalid_training_file", Message: "bad file", Param: "training_file"}, job.Error)
+}
+
+func TestFineTuningJobsEndpoints(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, `{"id": "ftjob-1", "data": [], "has_more": false}`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	api := New(Config{BaseURL: server.URL}).FineTuningJobs()
+
+	_, err := api.GetByID("ftjob-1")
+	require.NoError(t, err)
+	_, err = api.Pause("ftjob-1")
+	require.NoError(t, err)
+	_, err = api.Resume("ftjob-1")
+	require.NoError(t, err)
+	_, err = api.Cancel("ftjob-1")
+	require.NoError(t, err)
+
+	jobs := api.List(ctx, FineTuningJobListParams{ListParams: ListParams{Limit: 5}, Metadata: map[string]string{"team": "ml"}})
+	events := api.ListEvents(ctx, "ftjob-1", ListParams{})
+	checkpoints := api.ListCheckpoints(ctx, "ftjob-1", ListParams{})
+
+	assert.False(t, jobs.Next())
+	assert.False(t, events.Next())
+	assert.False(t, checkpoints.Next())
+	require.NoError(t, jobs.Err())
+
+	assert.Equal(t, []string{
+		"GET /fine_tuning/jobs/ftjob-1?",
+		"POST /fine_tuning/jobs/ftjob-1/pause?",
+		"POST /fine_tuning/jobs/ftjob-1/resume?",
+		"POST /fine_tuning/jobs/ftjob-1/cancel?",
+		"GET /fine_tuning/jobs?limit=5&metadata%5Bteam%5D=ml",
+		"GET /fine_tuning/jobs/ftjob-1/events?",
+		"GET /fine_tuning/jobs/ftjob-1/checkpoints?",
+	}, requests)
+}
@@ -2,20 +2,38 @@
 package gopenai
 
 import (
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 const (
-	baseURL               = "https://api.openai.com/v1"
+	defaultBaseURL        = "https://api.openai.com/v1"
 	defaultRequestTimeout = time.Second * 30
+	defaultKeepAlive      = time.Second * 30
 )
 
 // Config holds the configuration values for the OpenAI client.
 type Config struct {
 	APIKey         string
 	OrganizationID string
+	// RequestTimeout bounds every request attempt, from connecting to
+	// reading the whole response body. Streams are the exception: it
+	// only bounds connecting and waiting for their response headers,
+	// and the events are then received until the context is done.
+	// Defaults to 30 seconds.
 	RequestTimeout time.Duration
+	// BaseURL is the URL all endpoint paths are joined to. It can point
+	// to any OpenAI compatible server. Defaults to the OpenAI API URL.
+	BaseURL string
+	// Headers are extra headers sent with every request.
+	Headers http.Header
+	// QueryParams are extra query parameters sent with every request.
+	QueryParams url.Values
+	// RetryPolicy configures the retrying of failed requests.
+	// Retries are disabled unless RetryPolicy.MaxAttempts is at least 2.
+	RetryPolicy RetryPolicy
 }
 
 // Client is the interface for interacting with the OpenAI API.
@@ -34,23 +52,51 @@ type Client interface {
 	Embeddings() EmbeddingsAPI
 	// Files returns the FilesAPI for interacting with files.
 	Files() FilesAPI
+	// Uploads returns the UploadsAPI for uploading files in multiple parts.
+	Uploads() UploadsAPI
 	// FineTunes returns the FineTunesAPI for interacting with fine-tunes.
 	FineTunes() FineTunesAPI
+	// FineTuningJobs returns the FineTuningJobsAPI for interacting with fine-tuning jobs.
+	FineTuningJobs() FineTuningJobsAPI
 	// Moderations returns the ModerationsAPI for interacting with moderations.
 	Moderations() ModerationsAPI
 }
 
 // New returns a new OpenAI client with the given configuration.
 // If RequestTimeout is not set in the config, it will be set to defaultRequestTimeout.
+// If BaseURL is not set in the config, it will be set to defaultBaseURL.
 func New(cfg Config) Client {
 	if cfg.RequestTimeout == 0 {
 		cfg.RequestTimeout = defaultRequestTimeout
 	}
 
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+
+	cfg.RetryPolicy = cfg.RetryPolicy.withDefaults()
+
 	return client{
 		cfg: cfg,
 		httpClient: &http.Client{
 			Timeout: cfg.RequestTimeout,
 		},
+		streamHTTPClient: newStreamHTTPClient(cfg.RequestTimeout),
+	}
+}
+
+// newStreamHTTPClient returns an http client whose timeout covers dialing,
+// the TLS handshake and waiting for the response headers, but not reading
+// the response body, for streams that last as long as their context.
+func newStreamHTTPClient(timeout time.Duration) *http.Client {
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = defaultTransport.Clone()
 	}
+
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: defaultKeepAlive}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+
+	return &http.Client{Transport: transport}
 }
@@ -1,8 +1,8 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -56,9 +56,9 @@ type ImageGenerationParams struct {
 // ImageEditParams are the parameters for editing existing images.
 type ImageEditParams struct {
 	// Image is the image to edit.
-	Image string `mapstructure:"image"`
+	Image *FileUpload `mapstructure:"image"`
 	// Mask is the image to use as a mask for the edit.
-	Mask string `mapstructure:"mask,omitempty"`
+	Mask *FileUpload `mapstructure:"mask,omitempty"`
 	// Prompt is the prompt text used to edit the images.
 	Prompt string `mapstructure:"prompt"`
 	// N is the number of images to generate.
@@ -75,7 +75,7 @@ type ImageEditParams struct {
 // variations of existing images.
 type ImageVariationParams struct {
 	// Image is the image to generate variations from.
-	Image string `mapstructure:"image"`
+	Image *FileUpload `mapstructure:"image"`
 	// N is the number of images to generate.
 	N uint `mapstructure:"n,omitempty"`
 	// Size is the size of the images.
@@ -92,10 +92,17 @@ type ImageVariationParams struct {
 type ImagesAPI interface {
 	// Create generates new images based on the given prompt.
 	Create(ImageGenerationParams) ([]Image, error)
+	// CreateWithContext is like Create but uses the given context.
+	CreateWithContext(context.Context, ImageGenerationParams) ([]Image, error)
 	// Edit modifies an existing image based on the given prompt and mask.
 	Edit(ImageEditParams) ([]Image, error)
+	// EditWithContext is like Edit but uses the given context.
+	EditWithContext(context.Context, ImageEditParams) ([]Image, error)
 	// CreateVariations generates variations of an existing image.
 	CreateVariations(ImageVariationParams) ([]Image, error)
+	// CreateVariationsWithContext is like CreateVariations
+	// but uses the given context.
+	CreateVariationsWithContext(context.Context, ImageVariationParams) ([]Image, error)
 }
 
 type imagesAPI struct {
@@ -103,8 +110,12 @@ type imagesAPI struct {
 }
 
 func (api imagesAPI) Create(params ImageGenerationParams) ([]Image, error) {
-	url := fmt.Sprintf("%s%s", baseURL, imageGenerationsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api imagesAPI) CreateWithContext(ctx context.Context, params ImageGenerationParams) ([]Image, error) {
+	url := api.c.endpointURL(imageGenerationsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return nil, err
 	}
@@ -121,24 +132,32 @@ func (api imagesAPI) Create(params ImageGenerationParams) ([]Image, error) {
 }
 
 func (api imagesAPI) Edit(params ImageEditParams) ([]Image, error) {
-	url := fmt.Sprintf("%s%s", baseURL, imageEditsAPIEndpoint)
+	return api.EditWithContext(context.Background(), params)
+}
 
-	return api.imagesFromFormData(url, params)
+func (api imagesAPI) EditWithContext(ctx context.Context, params ImageEditParams) ([]Image, error) {
+	url := api.c.endpointURL(imageEditsAPIEndpoint)
+
+	return api.imagesFromFormData(ctx, url, params)
 }
 
 func (api imagesAPI) CreateVariations(params ImageVariationParams) ([]Image, error) {
-	url := fmt.Sprintf("%s%s", baseURL, imageVariationsAPIEndpoint)
+	return api.CreateVariationsWithContext(context.Background(), params)
+}
+
+func (api imagesAPI) CreateVariationsWithContext(ctx context.Context, params ImageVariationParams) ([]Image, error) {
+	url := api.c.endpointURL(imageVariationsAPIEndpoint)
 
-	return api.imagesFromFormData(url, params)
+	return api.imagesFromFormData(ctx, url, params)
 }
 
-func (api imagesAPI) imagesFromFormData(url string, params interface{}) ([]Image, error) {
-	data, contentType, err := structToMultipartFormData(params)
+func (api imagesAPI) imagesFromFormData(ctx context.Context, url string, params interface{}) ([]Image, error) {
+	body, contentType, err := structToMultipartBody(params)
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := api.c.getRequestResponse(url, http.MethodPost, data, contentType)
+	r, err := api.c.getRequestResponse(ctx, url, http.MethodPost, body, contentType)
 	if err != nil {
 		return nil, err
 	}
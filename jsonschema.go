@@ -0,0 +1,775 @@
+package gopenai
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tagNameJSON        = "json"
+	tagNameDescription = "description"
+	tagNameEnum        = "enum"
+	tagNameMinimum     = "minimum"
+	tagNameMaximum     = "maximum"
+	tagNameRequired    = "required"
+
+	jsonSchemaFormatDateTime = "date-time"
+)
+
+// JSONSchemaType is an enum type representing the type of a JSON value.
+type JSONSchemaType string
+
+// JSONSchemaType enum values
+const (
+	JSONSchemaTypeObject  JSONSchemaType = "object"
+	JSONSchemaTypeArray   JSONSchemaType = "array"
+	JSONSchemaTypeString  JSONSchemaType = "string"
+	JSONSchemaTypeNumber  JSONSchemaType = "number"
+	JSONSchemaTypeInteger JSONSchemaType = "integer"
+	JSONSchemaTypeBoolean JSONSchemaType = "boolean"
+	JSONSchemaTypeNull    JSONSchemaType = "null"
+)
+
+var (
+	// ErrJSONSchemaRecursiveType is returned when generating the JSON
+	// Schema of a type that refers to itself.
+	ErrJSONSchemaRecursiveType = errors.New("recursive types are not supported")
+
+	timeType           = reflect.TypeOf(time.Time{})
+	rawMessageType     = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	schemaProviderType = reflect.TypeOf((*JSONSchemaProvider)(nil)).Elem()
+)
+
+// JSONSchemaProvider is implemented by types that provide their own
+// JSON Schema, which is used in place of the generated one. The method
+// is called on the zero value of the type, or on a pointer to it.
+type JSONSchemaProvider interface {
+	JSONSchema() *JSONSchema
+}
+
+// JSONSchema represents a JSON Schema describing the parameters of
+// a tool or the format of a structured response. An empty Type
+// matches any value.
+type JSONSchema struct {
+	// Type is the type of the value.
+	Type JSONSchemaType `json:"type,omitempty"`
+	// Nullable allows the value to also be null. It is encoded as
+	// part of the type, for example ["string", "null"].
+	Nullable bool `json:"-"`
+	// Description describes the value to the model.
+	Description string `json:"description,omitempty"`
+	// Format is the format of a string value, such as "date-time".
+	Format string `json:"format,omitempty"`
+	// Enum lists the values allowed.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Minimum is the minimum allowed for a number.
+	Minimum *float64 `json:"minimum,omitempty"`
+	// Maximum is the maximum allowed for a number.
+	Maximum *float64 `json:"maximum,omitempty"`
+	// Properties are the schemas of the properties of an object.
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	// PropertyOrder is the order the properties were declared in.
+	PropertyOrder []string `json:"-"`
+	// Required lists the properties an object must have.
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is the schema of the properties of an object
+	// that are not listed in Properties. Nil allows no additional properties
+	// for objects with Properties and any for objects without.
+	AdditionalProperties *JSONSchema `json:"-"`
+	// Items is the schema of the items of an array.
+	Items *JSONSchema `json:"items,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. Properties
+// are encoded in PropertyOrder, followed by any unlisted ones.
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	type schema JSONSchema
+
+	var typ interface{}
+	if s.Type != "" {
+		typ = s.Type
+		if s.Nullable {
+			typ = []JSONSchemaType{s.Type, JSONSchemaTypeNull}
+		}
+	}
+
+	var additionalProperties interface{}
+	if s.Type == JSONSchemaTypeObject {
+		switch {
+		case s.AdditionalProperties != nil:
+			additionalProperties = s.AdditionalProperties
+		case s.Properties != nil:
+			additionalProperties = false
+		}
+	}
+
+	var properties json.RawMessage
+	if s.Properties != nil {
+		var err error
+		if properties, err = s.marshalProperties(); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(struct {
+		schema
+		Type                 interface{}     `json:"type,omitempty"`
+		Properties           json.RawMessage `json:"properties,omitempty"`
+		AdditionalProperties interface{}     `json:"additionalProperties,omitempty"`
+	}{schema(s), typ, properties, additionalProperties})
+}
+
+// propertyNames returns the names of the properties in
+// PropertyOrder, followed by any unlisted ones sorted by name.
+func (s JSONSchema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	listed := map[string]bool{}
+
+	for _, name := range s.PropertyOrder {
+		if _, ok := s.Properties[name]; ok && !listed[name] {
+			names = append(names, name)
+			listed[name] = true
+		}
+	}
+
+	unlisted := make([]string, 0, len(s.Properties)-len(names))
+	for name := range s.Properties {
+		if !listed[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+
+	sort.Strings(unlisted)
+
+	return append(names, unlisted...)
+}
+
+func (s JSONSchema) marshalProperties() (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, name := range s.propertyNames() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(s.Properties[name])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// clone returns a deep copy of the schema.
+func (s *JSONSchema) clone() *JSONSchema {
+	if s == nil {
+		return nil
+	}
+
+	c := *s
+	c.Enum = append([]interface{}(nil), s.Enum...)
+	c.PropertyOrder = append([]string(nil), s.PropertyOrder...)
+	c.Required = append([]string(nil), s.Required...)
+	c.AdditionalProperties = s.AdditionalProperties.clone()
+	c.Items = s.Items.clone()
+
+	if s.Minimum != nil {
+		minimum := *s.Minimum
+		c.Minimum = &minimum
+	}
+
+	if s.Maximum != nil {
+		maximum := *s.Maximum
+		c.Maximum = &maximum
+	}
+
+	if s.Properties != nil {
+		c.Properties = make(map[string]*JSONSchema, len(s.Properties))
+		for name, property := range s.Properties {
+			c.Properties[name] = property.clone()
+		}
+	}
+
+	return &c
+}
+
+// Strict returns a copy of the schema that follows the rules of the
+// strict mode of structured outputs: every property is required, the
+// ones that were optional become nullable and no additional properties
+// are allowed. It returns false if the schema uses features strict mode
+// does not support, such as maps or values of any type.
+func (s *JSONSchema) Strict() (*JSONSchema, bool) {
+	if s.Type == "" {
+		return nil, false
+	}
+
+	strict := *s
+	if s.Type == JSONSchemaTypeObject && (s.Properties == nil || s.AdditionalProperties != nil) {
+		return nil, false
+	}
+
+	if s.Items != nil {
+		items, ok := s.Items.Strict()
+		if !ok {
+			return nil, false
+		}
+
+		strict.Items = items
+	}
+
+	if s.Properties == nil {
+		return &strict, true
+	}
+
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	strict.PropertyOrder = s.propertyNames()
+	strict.Required = append([]string(nil), strict.PropertyOrder...)
+	strict.Properties = make(map[string]*JSONSchema, len(s.Properties))
+
+	for name, property := range s.Properties {
+		strictProperty, ok := property.Strict()
+		if !ok {
+			return nil, false
+		}
+
+		if !required[name] {
+			strictProperty.Nullable = true
+			strictProperty.Enum = nullableEnum(strictProperty.Enum)
+		}
+
+		strict.Properties[name] = strictProperty
+	}
+
+	return &strict, true
+}
+
+// GenerateJSONSchema returns the JSON Schema of the type of v, which
+// is usually a struct or a pointer to one. Struct fields are named after
+// their json tag and are required unless the tag has the omitempty option
+// or the field is a pointer, which is also nullable. The description, enum
+// (comma separated), minimum, maximum and required ("true" or "false")
+// tags add the matching schema keywords, the enum of a slice applying
+// to its items. Types implementing JSONSchemaProvider provide their own
+// schema, json.RawMessage matches any value and other
+// encoding.TextMarshaler types are strings.
+func GenerateJSONSchema(v interface{}) (*JSONSchema, error) {
+	return generateRootJSONSchema(reflect.TypeOf(v))
+}
+
+func generateRootJSONSchema(t reflect.Type) (*JSONSchema, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return generateJSONSchema(t, map[reflect.Type]bool{})
+}
+
+func generateJSONSchema(t reflect.Type, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	if t == nil {
+		return &JSONSchema{}, nil
+	}
+
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(schemaProviderType) {
+		provided := reflect.New(t).Interface().(JSONSchemaProvider).JSONSchema()
+		if provided == nil {
+			return nil, fmt.Errorf("nil JSON Schema provided by %s", t)
+		}
+
+		// copied so that neither the tags of a field nor Strict
+		// alter the provided schema
+		return provided.clone(), nil
+	}
+
+	switch {
+	case t == rawMessageType:
+		return &JSONSchema{}, nil
+	case t == timeType:
+		return &JSONSchema{Type: JSONSchemaTypeString, Format: jsonSchemaFormatDateTime}, nil
+	case t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textMarshalerType):
+		return &JSONSchema{Type: JSONSchemaTypeString}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema, err := generateJSONSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		schema.Nullable = schema.Type != ""
+
+		return schema, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: JSONSchemaTypeBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: JSONSchemaTypeInteger}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: JSONSchemaTypeNumber}, nil
+	case reflect.String:
+		return &JSONSchema{Type: JSONSchemaTypeString}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		// byte slices are encoded as base64 strings
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: JSONSchemaTypeString}, nil
+		}
+
+		items, err := generateJSONSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		return &JSONSchema{Type: JSONSchemaTypeArray, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+
+		values, err := generateJSONSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		return &JSONSchema{Type: JSONSchemaTypeObject, AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("%w: %s", ErrJSONSchemaRecursiveType, t)
+		}
+
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		schema := &JSONSchema{
+			Type:       JSONSchemaTypeObject,
+			Properties: map[string]*JSONSchema{},
+		}
+
+		if err := addStructProperties(schema, t, visiting); err != nil {
+			return nil, err
+		}
+
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func addStructProperties(schema *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get(tagNameJSON)
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// promote the fields of untagged embedded structs like encoding/json does
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if err := addStructProperties(schema, fieldType, visiting); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := generateJSONSchema(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if err := applyJSONSchemaTags(property, field.Tag); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if _, ok := schema.Properties[name]; !ok {
+			schema.PropertyOrder = append(schema.PropertyOrder, name)
+		}
+
+		schema.Properties[name] = property
+
+		required := !strings.Contains(","+opts+",", ",omitempty,") && field.Type.Kind() != reflect.Ptr
+		if value, ok := field.Tag.Lookup(tagNameRequired); ok {
+			required = value == "true"
+		}
+
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return nil
+}
+
+func applyJSONSchemaTags(schema *JSONSchema, tag reflect.StructTag) error {
+	schema.Description = tag.Get(tagNameDescription)
+
+	if value, ok := tag.Lookup(tagNameEnum); ok {
+		// the enum of a slice lists the values allowed for its items
+		target := schema
+		if schema.Type == JSONSchemaTypeArray && schema.Items != nil {
+			target = schema.Items
+		}
+
+		for _, item := range strings.Split(value, ",") {
+			enumValue, err := parseJSONSchemaValue(target.Type, strings.TrimSpace(item))
+			if err != nil {
+				return fmt.Errorf("invalid enum value %q: %w", item, err)
+			}
+
+			target.Enum = append(target.Enum, enumValue)
+		}
+
+		if target.Nullable {
+			target.Enum = nullableEnum(target.Enum)
+		}
+	}
+
+	for tagName, dst := range map[string]**float64{
+		tagNameMinimum: &schema.Minimum,
+		tagNameMaximum: &schema.Maximum,
+	} {
+		value, ok := tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", tagName, value, err)
+		}
+
+		*dst = &f
+	}
+
+	return nil
+}
+
+func parseJSONSchemaValue(typ JSONSchemaType, value string) (interface{}, error) {
+	switch typ {
+	case JSONSchemaTypeInteger:
+		return strconv.ParseInt(value, 10, 64)
+	case JSONSchemaTypeNumber:
+		return strconv.ParseFloat(value, 64)
+	case JSONSchemaTypeBoolean:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// JSONSchemaViolation describes a value not matching its schema.
+type JSONSchemaViolation struct {
+	// Path is the location of the value, such as "$.items[2].name".
+	Path string
+	// Message describes how the value does not match its schema.
+	Message string
+}
+
+// JSONSchemaValidationError is returned when a value does
+// not match its schema. Its message is meant to be clear
+// enough to be sent back to the model.
+type JSONSchemaValidationError struct {
+	// Violations lists every mismatch found.
+	Violations []JSONSchemaViolation
+}
+
+// Error returns the string representation of the error.
+func (e *JSONSchemaValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", v.Path, v.Message))
+	}
+
+	return "value does not match schema: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks that the JSON document in data matches the schema.
+// It returns a *JSONSchemaValidationError listing the mismatches, or
+// the decoding error if data is not valid JSON.
+func (s *JSONSchema) Validate(data []byte) error {
+	var v interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	validationErr := &JSONSchemaValidationError{}
+	s.validate("$", v, validationErr)
+
+	if len(validationErr.Violations) > 0 {
+		return validationErr
+	}
+
+	return nil
+}
+
+func (s *JSONSchema) validate(path string, v interface{}, validationErr *JSONSchemaValidationError) {
+	addViolation := func(format string, args ...interface{}) {
+		validationErr.Violations = append(validationErr.Violations,
+			JSONSchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if v == nil {
+		if s.Type != "" && !s.Nullable {
+			addViolation("must be of type %s, got null", s.Type)
+		}
+
+		return
+	}
+
+	switch s.Type {
+	case "":
+		return
+	case JSONSchemaTypeObject:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			addViolation("must be an object")
+
+			return
+		}
+
+		s.validateObject(path, obj, validationErr)
+	case JSONSchemaTypeArray:
+		arr, ok := v.([]interface{})
+		if !ok {
+			addViolation("must be an array")
+
+			return
+		}
+
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, validationErr)
+			}
+		}
+	case JSONSchemaTypeString:
+		str, ok := v.(string)
+		if !ok {
+			addViolation("must be a string")
+
+			return
+		}
+
+		if s.Format == jsonSchemaFormatDateTime {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				addViolation("must be an RFC 3339 date-time")
+			}
+		}
+	case JSONSchemaTypeNumber, JSONSchemaTypeInteger:
+		num, ok := v.(json.Number)
+		if !ok {
+			addViolation("must be a number")
+
+			return
+		}
+
+		f, err := num.Float64()
+		if err != nil {
+			addViolation("must be a number")
+
+			return
+		}
+
+		if s.Type == JSONSchemaTypeInteger && f != math.Trunc(f) {
+			addViolation("must be an integer")
+		}
+
+		if s.Minimum != nil && f < *s.Minimum {
+			addViolation("must be >= %v", *s.Minimum)
+		}
+
+		if s.Maximum != nil && f > *s.Maximum {
+			addViolation("must be <= %v", *s.Maximum)
+		}
+	case JSONSchemaTypeBoolean:
+		if _, ok := v.(bool); !ok {
+			addViolation("must be a boolean")
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.enumContains(v) {
+		addViolation("must be one of %s", s.enumString())
+	}
+}
+
+func (s *JSONSchema) validateObject(path string, obj map[string]interface{}, validationErr *JSONSchemaValidationError) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			validationErr.Violations = append(validationErr.Violations,
+				JSONSchemaViolation{Path: path + "." + name, Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		switch {
+		case ok:
+		case s.AdditionalProperties != nil:
+			property = s.AdditionalProperties
+		case s.Properties != nil:
+			validationErr.Violations = append(validationErr.Violations,
+				JSONSchemaViolation{Path: path + "." + name, Message: "is not an allowed property"})
+
+			continue
+		default:
+			continue
+		}
+
+		property.validate(path+"."+name, obj[name], validationErr)
+	}
+}
+
+func (s *JSONSchema) enumContains(v interface{}) bool {
+	for _, enumValue := range s.Enum {
+		num, ok := v.(json.Number)
+		if !ok {
+			if v == enumValue {
+				return true
+			}
+
+			continue
+		}
+
+		f, err := num.Float64()
+		if err != nil {
+			continue
+		}
+
+		switch n := enumValue.(type) {
+		case int64:
+			if f == float64(n) {
+				return true
+			}
+		case float64:
+			if f == n {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// nullableEnum returns a copy of enum that also allows null, or
+// enum itself if it is empty or already allows null.
+func nullableEnum(enum []interface{}) []interface{} {
+	if len(enum) == 0 {
+		return enum
+	}
+
+	for _, v := range enum {
+		if v == nil {
+			return enum
+		}
+	}
+
+	return append(append(make([]interface{}, 0, len(enum)+1), enum...), nil)
+}
+
+func (s *JSONSchema) enumString() string {
+	values := make([]string, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		if v == nil {
+			values = append(values, "null")
+
+			continue
+		}
+
+		values = append(values, fmt.Sprintf("%v", v))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// DecodeToolArguments validates the arguments generated by the model
+// against the JSON Schema of dst's type and decodes them into dst,
+// which must be a pointer. It returns a *JSONSchemaValidationError
+// if the arguments do not match the schema.
+func DecodeToolArguments(arguments string, dst interface{}) error {
+	t := reflect.TypeOf(dst)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return fmt.Errorf("destination must be a pointer, got %T", dst)
+	}
+
+	schema, err := generateRootJSONSchema(t)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Validate([]byte(arguments)); err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(arguments), dst)
+}
+
+// DecodeArguments validates the arguments of the function call and
+// decodes them into dst. See DecodeToolArguments.
+func (c ChatCompletionFunctionCall) DecodeArguments(dst interface{}) error {
+	return DecodeToolArguments(c.Arguments, dst)
+}
+
+// NewFunctionToolFor returns a function tool whose parameters
+// are the JSON Schema generated from the type of params.
+func NewFunctionToolFor(name, description string, params interface{}) (ChatCompletionTool, error) {
+	schema, err := GenerateJSONSchema(params)
+	if err != nil {
+		return ChatCompletionTool{}, err
+	}
+
+	return NewFunctionTool(name, description, schema), nil
+}
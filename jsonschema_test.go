@@ -0,0 +1,248 @@
+package gopenai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWeatherLocation struct {
+	City    string `json:"city" description:"The city name"`
+	Country string `json:"country,omitempty"`
+}
+
+type testWeatherParams struct {
+	Location testWeatherLocation `json:"location"`
+	Unit     string              `json:"unit" enum:"celsius,fahrenheit"`
+	Days     int                 `json:"days" minimum:"1" maximum:"10"`
+	Hours    []int               `json:"hours,omitempty"`
+	Labels   map[string]string   `json:"labels,omitempty"`
+	Since    *time.Time          `json:"since"`
+	Detailed bool                `json:"detailed,omitempty" required:"true"`
+	internal string
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	schema, err := GenerateJSONSchema(testWeatherParams{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	expected := `{
+		"type": "object",
+		"properties": {
+			"location": {
+				"type": "object",
+				"properties": {
+					"city": {"type": "string", "description": "The city name"},
+					"country": {"type": "string"}
+				},
+				"required": ["city"],
+				"additionalProperties": false
+			},
+			"unit": {"type": "string", "enum": ["celsius", "fahrenheit"]},
+			"days": {"type": "integer", "minimum": 1, "maximum": 10},
+			"hours": {"type": "array", "items": {"type": "integer"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"since": {"type": ["string", "null"], "format": "date-time"},
+			"detailed": {"type": "boolean"}
+		},
+		"required": ["location", "unit", "days", "detailed"],
+		"additionalProperties": false
+	}`
+
+	assert.JSONEq(t, expected, string(data))
+	assert.Equal(t, []string{"location", "unit", "days", "hours", "labels", "since", "detailed"}, schema.PropertyOrder)
+}
+
+type testColor struct {
+	r, g, b uint8
+}
+
+func (c testColor) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)), nil
+}
+
+type testPercent float64
+
+var testPercentSchema = &JSONSchema{Type: JSONSchemaTypeNumber, Minimum: Float64(0), Maximum: Float64(100)}
+
+func (testPercent) JSONSchema() *JSONSchema {
+	return testPercentSchema
+}
+
+type testNilSchema struct{}
+
+func (*testNilSchema) JSONSchema() *JSONSchema {
+	return nil
+}
+
+func TestGenerateJSONSchemaSpecialTypes(t *testing.T) {
+	type params struct {
+		Color   testColor       `json:"color"`
+		Address net.IP          `json:"address"`
+		Share   testPercent     `json:"share" description:"Share of the total"`
+		Ratio   *testPercent    `json:"ratio"`
+		Extra   json.RawMessage `json:"extra"`
+	}
+
+	schema, err := GenerateJSONSchema(params{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	expected := `{
+		"type": "object",
+		"properties": {
+			"color": {"type": "string"},
+			"address": {"type": "string"},
+			"share": {"type": "number", "description": "Share of the total", "minimum": 0, "maximum": 100},
+			"ratio": {"type": ["number", "null"], "minimum": 0, "maximum": 100},
+			"extra": {}
+		},
+		"required": ["color", "address", "share", "extra"],
+		"additionalProperties": false
+	}`
+
+	assert.JSONEq(t, expected, string(data))
+	assert.Empty(t, testPercentSchema.Description)
+	assert.False(t, testPercentSchema.Nullable)
+
+	_, err = GenerateJSONSchema(testNilSchema{})
+	assert.Error(t, err)
+}
+
+type testLevel string
+
+var testLevelSchema = &JSONSchema{Type: JSONSchemaTypeString, Enum: []interface{}{"low", "high"}}
+
+func (testLevel) JSONSchema() *JSONSchema {
+	return testLevelSchema
+}
+
+func TestJSONSchemaStrictEnums(t *testing.T) {
+	type params struct {
+		Level testLevel `json:"level,omitempty"`
+		Tags  []string  `json:"tags" enum:"red,green"`
+		Unit  *string   `json:"unit" enum:"celsius,fahrenheit"`
+	}
+
+	schema, err := GenerateJSONSchema(params{})
+	require.NoError(t, err)
+
+	strict, ok := schema.Strict()
+	require.True(t, ok)
+
+	data, err := json.Marshal(strict)
+	require.NoError(t, err)
+
+	expected := `{
+		"type": "object",
+		"properties": {
+			"level": {"type": ["string", "null"], "enum": ["low", "high", null]},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["red", "green"]}},
+			"unit": {"type": ["string", "null"], "enum": ["celsius", "fahrenheit", null]}
+		},
+		"required": ["level", "tags", "unit"],
+		"additionalProperties": false
+	}`
+
+	assert.JSONEq(t, expected, string(data))
+	assert.Equal(t, []interface{}{"low", "high"}, testLevelSchema.Enum)
+	assert.Equal(t, []interface{}{"low", "high"}, schema.Properties["level"].Enum)
+
+	assert.NoError(t, strict.Validate([]byte(`{"level": null, "tags": ["red"], "unit": null}`)))
+
+	var validationErr *JSONSchemaValidationError
+	require.ErrorAs(t, strict.Validate([]byte(`{"level": "medium", "tags": ["blue"], "unit": null}`)), &validationErr)
+	assert.Len(t, validationErr.Violations, 2)
+}
+
+func TestGenerateJSONSchemaErrors(t *testing.T) {
+	type recursive struct {
+		Children []recursive `json:"children"`
+	}
+
+	type invalidEnum struct {
+		Count int `json:"count" enum:"one"`
+	}
+
+	_, err := GenerateJSONSchema(recursive{})
+	assert.ErrorIs(t, err, ErrJSONSchemaRecursiveType)
+
+	_, err = GenerateJSONSchema(invalidEnum{})
+	assert.Error(t, err)
+
+	_, err = GenerateJSONSchema(map[int]string{})
+	assert.Error(t, err)
+}
+
+func TestDecodeToolArguments(t *testing.T) {
+	testCases := []struct {
+		name               string
+		arguments          string
+		expectedViolations []JSONSchemaViolation
+		expectErr          bool
+	}{
+		{
+			name:      "valid arguments",
+			arguments: `{"location": {"city": "Paris"}, "unit": "celsius", "days": 3, "since": null, "detailed": true}`,
+		},
+		{
+			name:      "invalid json",
+			arguments: `{"location": `,
+			expectErr: true,
+		},
+		{
+			name:      "schema violations",
+			arguments: `{"location": {"country": 1}, "unit": "kelvin", "days": 2.5, "hours": [1, "2"], "extra": 1}`,
+			expectedViolations: []JSONSchemaViolation{
+				{Path: "$.detailed", Message: "is required"},
+				{Path: "$.days", Message: "must be an integer"},
+				{Path: "$.extra", Message: "is not an allowed property"},
+				{Path: "$.hours[1]", Message: "must be a number"},
+				{Path: "$.location.city", Message: "is required"},
+				{Path: "$.location.country", Message: "must be a string"},
+				{Path: "$.unit", Message: "must be one of celsius, fahrenheit"},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "out of range",
+			arguments: `{"location": {"city": "Paris"}, "unit": "celsius", "days": 11, "detailed": false}`,
+			expectedViolations: []JSONSchemaViolation{
+				{Path: "$.days", Message: "must be <= 10"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var params testWeatherParams
+
+			err := ChatCompletionFunctionCall{Arguments: tc.arguments}.DecodeArguments(&params)
+			if !tc.expectErr {
+				require.NoError(t, err)
+				assert.Equal(t, "Paris", params.Location.City)
+
+				return
+			}
+
+			require.Error(t, err)
+
+			if tc.expectedViolations != nil {
+				var validationErr *JSONSchemaValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tc.expectedViolations, validationErr.Violations)
+			}
+		})
+	}
+}
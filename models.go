@@ -1,8 +1,8 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -66,10 +66,18 @@ type DeletedModel struct {
 type ModelsAPI interface {
 	// GetAll returns all available models.
 	GetAll() ([]Model, error)
+	// GetAllWithContext is like GetAll but uses the given context.
+	GetAllWithContext(ctx context.Context) ([]Model, error)
+	// List returns a pager over the available models.
+	List(ctx context.Context) *Pager[Model]
 	// GetByID returns the model with the specified ID.
 	GetByID(id string) (Model, error)
+	// GetByIDWithContext is like GetByID but uses the given context.
+	GetByIDWithContext(ctx context.Context, id string) (Model, error)
 	// DeleteByID deletes a model by its identifier
 	DeleteByID(id string) (DeletedModel, error)
+	// DeleteByIDWithContext is like DeleteByID but uses the given context
+	DeleteByIDWithContext(ctx context.Context, id string) (DeletedModel, error)
 }
 
 type modelsAPI struct {
@@ -77,8 +85,12 @@ type modelsAPI struct {
 }
 
 func (api modelsAPI) GetAll() ([]Model, error) {
-	url := fmt.Sprintf("%s%s", baseURL, modelsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetAllWithContext(context.Background())
+}
+
+func (api modelsAPI) GetAllWithContext(ctx context.Context) ([]Model, error) {
+	url := api.c.endpointURL(modelsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -94,9 +106,19 @@ func (api modelsAPI) GetAll() ([]Model, error) {
 	return response.Data, nil
 }
 
+func (api modelsAPI) List(ctx context.Context) *Pager[Model] {
+	url := api.c.endpointURL(modelsAPIEndpoint)
+
+	return newPager(ctx, api.c, url, nil, func(m Model) string { return m.ID })
+}
+
 func (api modelsAPI) GetByID(id string) (Model, error) {
-	url := fmt.Sprintf("%s%s/%s", baseURL, modelsAPIEndpoint, id)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodGet, nil)
+	return api.GetByIDWithContext(context.Background(), id)
+}
+
+func (api modelsAPI) GetByIDWithContext(ctx context.Context, id string) (Model, error) {
+	url := api.c.endpointURL(modelsAPIEndpoint, id)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return Model{}, err
 	}
@@ -110,8 +132,12 @@ func (api modelsAPI) GetByID(id string) (Model, error) {
 }
 
 func (api modelsAPI) DeleteByID(id string) (DeletedModel, error) {
-	url := fmt.Sprintf("%s%s/%s", baseURL, modelsAPIEndpoint, id)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodDelete, nil)
+	return api.DeleteByIDWithContext(context.Background(), id)
+}
+
+func (api modelsAPI) DeleteByIDWithContext(ctx context.Context, id string) (DeletedModel, error) {
+	url := api.c.endpointURL(modelsAPIEndpoint, id)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodDelete, nil)
 	if err != nil {
 		return DeletedModel{}, err
 	}
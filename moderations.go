@@ -1,8 +1,8 @@
 package gopenai
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -84,6 +84,7 @@ type ModerationParams struct {
 // ModerationsAPI is the interface for the OpenAI moderations API.
 type ModerationsAPI interface {
 	Create(ModerationParams) (Moderation, error)
+	CreateWithContext(context.Context, ModerationParams) (Moderation, error)
 }
 
 type moderationsAPI struct {
@@ -91,8 +92,12 @@ type moderationsAPI struct {
 }
 
 func (api moderationsAPI) Create(params ModerationParams) (Moderation, error) {
-	url := fmt.Sprintf("%s%s", baseURL, moderationsAPIEndpoint)
-	r, err := api.c.getJSONRequestResponse(url, http.MethodPost, params)
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api moderationsAPI) CreateWithContext(ctx context.Context, params ModerationParams) (Moderation, error) {
+	url := api.c.endpointURL(moderationsAPIEndpoint)
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
 	if err != nil {
 		return Moderation{}, err
 	}
@@ -0,0 +1,208 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListOrder is an enum type representing the order,
+// by creation time, in which list endpoints return items.
+type ListOrder string
+
+// ListOrder enum values
+const (
+	ListOrderAsc  ListOrder = "asc"
+	ListOrderDesc ListOrder = "desc"
+)
+
+// ListParams holds the cursor parameters of the list endpoints.
+type ListParams struct {
+	// Limit is the number of items fetched per page.
+	Limit int
+	// After is the ID of the item after which the listing starts.
+	After string
+	// Order is the order in which the items are listed.
+	Order ListOrder
+}
+
+func (p ListParams) query() url.Values {
+	query := url.Values{}
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+
+	if p.After != "" {
+		query.Set("after", p.After)
+	}
+
+	if p.Order != "" {
+		query.Set("order", string(p.Order))
+	}
+
+	return query
+}
+
+// Page represents a page of items returned by a list endpoint.
+type Page[T any] struct {
+	// Data holds the items of the page.
+	Data []T `json:"data"`
+	// HasMore is whether there are items after the page.
+	HasMore bool `json:"has_more"`
+	// FirstID is the ID of the first item of the page.
+	FirstID string `json:"first_id"`
+	// LastID is the ID of the last item of the page.
+	LastID string `json:"last_id"`
+}
+
+// Pager iterates over the items of a list endpoint, fetching
+// each page lazily once the previous one has been consumed.
+//
+//	pager := client.Files().List(ctx, FileListParams{})
+//	for pager.Next() {
+//		file := pager.Current()
+//	}
+//
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+type Pager[T any] struct {
+	ctx    context.Context
+	c      client
+	url    string
+	query  url.Values
+	cursor func(T) string
+
+	page    Page[T]
+	index   int
+	fetched bool
+	err     error
+}
+
+func newPager[T any](ctx context.Context, c client, reqURL string, query url.Values, cursor func(T) string) *Pager[T] {
+	return &Pager[T]{
+		ctx:    ctx,
+		c:      c,
+		url:    reqURL,
+		query:  query,
+		cursor: cursor,
+		index:  -1,
+	}
+}
+
+// Next advances the pager to the next item, fetching the next page
+// if needed. It returns false once all the items have been iterated
+// over or an error occurred, which is then returned by Err.
+func (p *Pager[T]) Next() bool {
+	if p.err != nil {
+		return false
+	}
+
+	p.index++
+	for p.index >= len(p.page.Data) {
+		after, ok := p.nextCursor()
+		if !ok {
+			return false
+		}
+
+		if err := p.fetch(after); err != nil {
+			p.err = err
+
+			return false
+		}
+
+		p.index = 0
+	}
+
+	return true
+}
+
+// Current returns the item the pager is at.
+func (p *Pager[T]) Current() T {
+	if p.index < 0 || p.index >= len(p.page.Data) {
+		var zero T
+
+		return zero
+	}
+
+	return p.page.Data[p.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// nextCursor returns the cursor the next page starts after
+// and whether there is a next page to fetch.
+func (p *Pager[T]) nextCursor() (string, bool) {
+	if !p.fetched {
+		return p.query.Get("after"), true
+	}
+
+	if !p.page.HasMore || len(p.page.Data) == 0 {
+		return "", false
+	}
+
+	if p.page.LastID != "" {
+		return p.page.LastID, true
+	}
+
+	if p.cursor == nil {
+		return "", false
+	}
+
+	return p.cursor(p.page.Data[len(p.page.Data)-1]), true
+}
+
+func (p *Pager[T]) fetch(after string) error {
+	if err := p.ctx.Err(); err != nil {
+		return requestError(p.ctx, err)
+	}
+
+	query := url.Values{}
+	for k, values := range p.query {
+		query[k] = values
+	}
+
+	if after != "" {
+		query.Set("after", after)
+	}
+
+	reqURL := p.url
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	r, err := p.c.getJSONRequestResponse(p.ctx, reqURL, http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+
+	var page Page[T]
+	if err := json.Unmarshal(r, &page); err != nil {
+		return err
+	}
+
+	p.page = page
+	p.fetched = true
+
+	return nil
+}
+
+// Collect iterates over the remaining items of the pager, fetching
+// every page, and returns them.
+func (p *Pager[T]) Collect() ([]T, error) {
+	var items []T
+	for p.Next() {
+		items = append(items, p.Current())
+	}
+
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package gopenai
+
+import "iter"
+
+// All returns an iterator over the remaining items of the pager, along
+// with the error that stopped the iteration, if any, as the last pair.
+// Pages are fetched lazily and stopping the iteration early stops
+// the fetching.
+//
+//	for file, err := range client.Files().List(ctx, FileListParams{}).All() {
+//		if err != nil {
+//			...
+//		}
+//	}
+func (p *Pager[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.Next() {
+			if !yield(p.Current(), nil) {
+				return
+			}
+		}
+
+		if err := p.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
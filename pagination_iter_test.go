@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package gopenai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerAll(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	var ids []string
+	for file, err := range New(Config{BaseURL: server.URL}).Files().List(context.Background(), FileListParams{}).All() {
+		require.NoError(t, err)
+		ids = append(ids, file.ID)
+	}
+
+	assert.Equal(t, []string{"f1", "f2", "f3"}, ids)
+}
+
+func TestPagerAllStopsEarly(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	for file := range New(Config{BaseURL: server.URL}).Files().List(context.Background(), FileListParams{}).All() {
+		assert.Equal(t, "f1", file.ID)
+
+		break
+	}
+
+	assert.Len(t, queries, 1)
+}
@@ -0,0 +1,104 @@
+package gopenai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFilesServer(t *testing.T, queries *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.RawQuery)
+
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		switch r.URL.Query().Get("after") {
+		case "":
+			_, _ = io.WriteString(w, `{"data": [{"id": "f1"}, {"id": "f2"}], "has_more": true}`)
+		case "f2":
+			_, _ = io.WriteString(w, `{"data": [{"id": "f3"}], "has_more": false}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestPagerNext(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	params := FileListParams{ListParams: ListParams{Limit: 2, Order: ListOrderDesc}, Purpose: "fine-tune"}
+	pager := New(Config{BaseURL: server.URL}).Files().List(context.Background(), params)
+
+	var ids []string
+	for pager.Next() {
+		ids = append(ids, pager.Current().ID)
+	}
+
+	require.NoError(t, pager.Err())
+	assert.Equal(t, []string{"f1", "f2", "f3"}, ids)
+	assert.Equal(t, []string{
+		"limit=2&order=desc&purpose=fine-tune",
+		"after=f2&limit=2&order=desc&purpose=fine-tune",
+	}, queries)
+}
+
+func TestPagerFetchesLazily(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	pager := New(Config{BaseURL: server.URL}).Files().List(context.Background(), FileListParams{})
+	assert.Empty(t, queries)
+
+	require.True(t, pager.Next())
+	require.True(t, pager.Next())
+	assert.Len(t, queries, 1)
+
+	require.True(t, pager.Next())
+	assert.Len(t, queries, 2)
+	assert.False(t, pager.Next())
+}
+
+func TestPagerCanceledContext(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pager := New(Config{BaseURL: server.URL}).Files().List(ctx, FileListParams{})
+
+	require.True(t, pager.Next())
+	cancel()
+	require.True(t, pager.Next())
+	assert.False(t, pager.Next())
+	assert.ErrorIs(t, pager.Err(), ErrRequestCanceled)
+	assert.Len(t, queries, 1)
+}
+
+func TestPagerCollect(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	files, err := New(Config{BaseURL: server.URL}).Files().List(context.Background(), FileListParams{}).Collect()
+	require.NoError(t, err)
+	assert.Equal(t, []File{{ID: "f1"}, {ID: "f2"}, {ID: "f3"}}, files)
+	assert.Len(t, queries, 2)
+}
+
+func TestFilesGetAll(t *testing.T) {
+	var queries []string
+	server := newTestFilesServer(t, &queries)
+
+	files, err := New(Config{BaseURL: server.URL}).Files().GetAll()
+	require.NoError(t, err)
+	assert.Equal(t, []File{{ID: "f1"}, {ID: "f2"}}, files)
+	assert.Equal(t, []string{""}, queries)
+}
@@ -0,0 +1,87 @@
+package gopenai
+
+import (
+	"io"
+	"time"
+)
+
+// progressInterval is the minimum interval between two progress
+// reports, apart from the last one which is always reported.
+const progressInterval = time.Millisecond * 100
+
+// Progress describes the progress of an upload or a download.
+type Progress struct {
+	// BytesDone is the number of bytes transferred so far.
+	BytesDone int64
+	// BytesTotal is the total number of bytes to transfer,
+	// or 0 when it is not known.
+	BytesTotal int64
+	// Rate is the average transfer rate, in bytes per second.
+	Rate float64
+	// ETA is the estimated time left until the transfer
+	// is done, or 0 when it cannot be estimated.
+	ETA time.Duration
+}
+
+// ProgressFunc is called with the progress of a transfer as it goes.
+type ProgressFunc func(Progress)
+
+// progressReader reports the progress of the reads of r
+// and records the error that ended them, if any.
+type progressReader struct {
+	r          io.Reader
+	onProgress ProgressFunc
+
+	done     int64
+	total    int64
+	start    time.Time
+	started  int64
+	reported time.Time
+	err      error
+}
+
+// newProgressReader returns a reader reporting the reads of r to
+// onProgress, if not nil, on top of the done bytes already transferred.
+func newProgressReader(r io.Reader, done, total int64, onProgress ProgressFunc) *progressReader {
+	return &progressReader{
+		r:          r,
+		onProgress: onProgress,
+		done:       done,
+		total:      total,
+		start:      time.Now(),
+		started:    done,
+	}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+
+	if err != nil && err != io.EOF {
+		p.err = err
+	}
+
+	if p.onProgress != nil {
+		now := time.Now()
+		if err != nil || now.Sub(p.reported) >= progressInterval {
+			p.reported = now
+			p.onProgress(p.progress(now))
+		}
+	}
+
+	return n, err
+}
+
+func (p *progressReader) progress(now time.Time) Progress {
+	progress := Progress{BytesDone: p.done, BytesTotal: p.total}
+
+	if elapsed := now.Sub(p.start).Seconds(); elapsed > 0 {
+		progress.Rate = float64(p.done-p.started) / elapsed
+	}
+
+	if progress.Rate > 0 && p.total > p.done {
+		progress.ETA = time.Duration(float64(p.total-p.done) / progress.Rate * float64(time.Second))
+	}
+
+	return progress
+}
@@ -0,0 +1,206 @@
+package gopenai
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultRetryBaseBackoff = time.Millisecond * 500
+	defaultRetryMaxBackoff  = time.Second * 30
+
+	headerNameRetryAfter              = "Retry-After"
+	headerNameRetryAfterMs            = "Retry-After-Ms"
+	headerNameRateLimitRemainingReqs  = "X-Ratelimit-Remaining-Requests"
+	headerNameRateLimitRemainingToken = "X-Ratelimit-Remaining-Tokens"
+	headerNameRateLimitResetReqs      = "X-Ratelimit-Reset-Requests"
+	headerNameRateLimitResetTokens    = "X-Ratelimit-Reset-Tokens"
+)
+
+// DefaultRetryableStatusCodes are the HTTP status codes that are
+// retried when RetryPolicy.RetryableStatusCodes is not set.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how failed requests are retried. Requests are
+// retried on network timeouts, connection errors, including connections
+// reset or closed by the server, and on responses with one of the
+// retryable status codes. Requests that are not idempotent, such as POST
+// requests, are only retried on timeouts and connection errors when none
+// of the request was written, unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the
+	// first one. Values lower than 2 disable retries.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry. It doubles
+	// with every attempt. Defaults to 500ms.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the computed backoff delay and the delays
+	// requested by the server. Defaults to 30s.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, of the backoff delay
+	// that is randomized to spread out retries of concurrent requests.
+	Jitter float64
+	// RetryableStatusCodes are the response status codes that are
+	// retried. Defaults to DefaultRetryableStatusCodes.
+	RetryableStatusCodes []int
+	// RetryNonIdempotent retries requests that are not idempotent on
+	// timeouts and connection errors even when the request may have
+	// reached the server, which may then process it more than once.
+	RetryNonIdempotent bool
+	// OnRetry, if set, is called before waiting for each retry.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// Delay is how long the client waits before the next attempt.
+	// Delays requested by the server through the Retry-After or
+	// x-ratelimit-reset-* headers take precedence over the backoff,
+	// up to RetryPolicy.MaxBackoff.
+	Delay time.Duration
+	// Err is the error of the failed attempt. Failed responses
+	// are reported as an *APIError.
+	Err error
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseBackoff <= 0 {
+		p.BaseBackoff = defaultRetryBaseBackoff
+	}
+
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+
+	if p.RetryableStatusCodes == nil {
+		p.RetryableStatusCodes = DefaultRetryableStatusCodes
+	}
+
+	return p
+}
+
+func (p RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return isTransientError(err)
+	}
+
+	for _, statusCode := range p.RetryableStatusCodes {
+		if resp.StatusCode == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTransientError reports whether err is a timeout or a connection
+// error, such as a refused connection or one reset or closed by the
+// server, after which the request may succeed when sent again.
+func isTransientError(err error) bool {
+	var opErr *net.OpError
+
+	return errors.Is(err, ErrRequestTimeout) ||
+		errors.As(err, &opErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxBackoff
+	if shift := attempt - 1; shift < 32 {
+		if d := p.BaseBackoff << shift; d > 0 && d < p.MaxBackoff {
+			delay = d
+		}
+	}
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay)) //nolint:gosec
+	}
+
+	return delay
+}
+
+// retryDelay returns the delay before retrying the given attempt: the
+// delay asked for by the server in the given response header, capped
+// by MaxBackoff, or the backoff of the attempt. The header may be nil.
+func (p RetryPolicy) retryDelay(attempt int, header http.Header) time.Duration {
+	serverDelay, ok := retryAfter(header)
+	if !ok {
+		return p.backoff(attempt)
+	}
+
+	if serverDelay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return serverDelay
+}
+
+// retryAfter returns the delay the server asked for through its
+// Retry-After headers or, when a rate limit is exhausted, through
+// the matching x-ratelimit-reset-* header.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(header.Get(headerNameRetryAfterMs), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+
+	if value := header.Get(headerNameRetryAfter); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+
+		if date, err := http.ParseTime(value); err == nil {
+			if delay := time.Until(date); delay > 0 {
+				return delay, true
+			}
+
+			return 0, true
+		}
+	}
+
+	var (
+		delay time.Duration
+		found bool
+	)
+
+	limits := [][2]string{
+		{headerNameRateLimitRemainingReqs, headerNameRateLimitResetReqs},
+		{headerNameRateLimitRemainingToken, headerNameRateLimitResetTokens},
+	}
+
+	for _, limit := range limits {
+		if header.Get(limit[0]) != "0" {
+			continue
+		}
+
+		reset, err := time.ParseDuration(header.Get(limit[1]))
+		if err != nil {
+			continue
+		}
+
+		if reset > delay {
+			delay = reset
+		}
+
+		found = true
+	}
+
+	return delay, found
+}
@@ -0,0 +1,323 @@
+package gopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRequestResponseRetries(t *testing.T) {
+	var (
+		attempts int
+		bodies   []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set(headerNameRetryAfterMs, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"message": "slow down", "type": "requests"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	defer server.Close()
+
+	var events []RetryEvent
+
+	c := client{
+		cfg: Config{
+			RetryPolicy: RetryPolicy{
+				MaxAttempts: 3,
+				BaseBackoff: time.Millisecond,
+				OnRetry: func(e RetryEvent) {
+					events = append(events, e)
+				},
+			}.withDefaults(),
+		},
+		httpClient: &http.Client{Timeout: time.Second * 5},
+	}
+
+	r, err := c.getJSONRequestResponse(context.Background(), server.URL, http.MethodPost, map[string]string{"a": "b"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"ok": true}`, string(r))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []string{`{"a":"b"}`, `{"a":"b"}`, `{"a":"b"}`}, bodies)
+	require.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Attempt)
+	assert.Equal(t, time.Millisecond, events[1].Delay)
+	assert.True(t, IsRateLimited(events[1].Err))
+}
+
+func TestGetRequestResponseRetriesExhausted(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	c := client{
+		cfg:        Config{RetryPolicy: RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}.withDefaults()},
+		httpClient: &http.Client{Timeout: time.Second * 5},
+	}
+
+	_, err := c.getRequestResponse(context.Background(), server.URL, http.MethodGet, nil, "")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		header        http.Header
+		expectedDelay time.Duration
+		expectedOK    bool
+	}{
+		{
+			name:          "retry-after seconds",
+			header:        http.Header{headerNameRetryAfter: []string{"2"}},
+			expectedDelay: time.Second * 2,
+			expectedOK:    true,
+		},
+		{
+			name:          "retry-after-ms takes precedence",
+			header:        http.Header{headerNameRetryAfter: []string{"2"}, headerNameRetryAfterMs: []string{"150"}},
+			expectedDelay: time.Millisecond * 150,
+			expectedOK:    true,
+		},
+		{
+			name: "exhausted token limit",
+			header: http.Header{
+				headerNameRateLimitRemainingReqs:  []string{"10"},
+				headerNameRateLimitResetReqs:      []string{"1m0s"},
+				headerNameRateLimitRemainingToken: []string{"0"},
+				headerNameRateLimitResetTokens:    []string{"6s"},
+			},
+			expectedDelay: time.Second * 6,
+			expectedOK:    true,
+		},
+		{
+			name:          "no headers",
+			header:        http.Header{},
+			expectedDelay: 0,
+			expectedOK:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delay, ok := retryAfter(tc.header)
+			assert.Equal(t, tc.expectedDelay, delay)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: time.Second * 5}
+
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, time.Second*4, p.backoff(3))
+	assert.Equal(t, time.Second*5, p.backoff(4))
+	assert.Equal(t, time.Second*5, p.backoff(100))
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		delay := p.backoff(2)
+		assert.True(t, delay > time.Second && delay <= time.Second*2)
+	}
+}
+
+func TestGetRequestResponseRetryAfterCapped(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set(headerNameRetryAfter, "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	var events []RetryEvent
+
+	c := client{
+		cfg: Config{RetryPolicy: RetryPolicy{
+			MaxAttempts: 2,
+			MaxBackoff:  time.Millisecond * 10,
+			OnRetry:     func(e RetryEvent) { events = append(events, e) },
+		}.withDefaults()},
+		httpClient: &http.Client{Timeout: time.Second * 5},
+	}
+
+	_, err := c.getRequestResponse(context.Background(), server.URL, http.MethodGet, nil, "")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, time.Millisecond*10, events[0].Delay)
+}
+
+func TestGetRequestResponseRetriesNonIdempotent(t *testing.T) {
+	testCases := []struct {
+		name               string
+		method             string
+		retryNonIdempotent bool
+		expectedAttempts   int32
+		expectedErr        error
+	}{
+		{
+			name:             "post is not sent again",
+			method:           http.MethodPost,
+			expectedAttempts: 1,
+			expectedErr:      ErrRequestTimeout,
+		},
+		{
+			name:               "post is sent again when allowed",
+			method:             http.MethodPost,
+			retryNonIdempotent: true,
+			expectedAttempts:   2,
+		},
+		{
+			name:             "get is sent again",
+			method:           http.MethodGet,
+			expectedAttempts: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					time.Sleep(time.Millisecond * 200)
+				}
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				BaseURL:        server.URL,
+				RequestTimeout: time.Millisecond * 50,
+				RetryPolicy: RetryPolicy{
+					MaxAttempts:        3,
+					BaseBackoff:        time.Millisecond,
+					RetryNonIdempotent: tc.retryNonIdempotent,
+				},
+			}).(client)
+
+			_, err := c.getRequestResponse(context.Background(), server.URL, tc.method, nil, "")
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestGetRequestResponseRetriesUnsentPost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	var events []RetryEvent
+
+	c := New(Config{
+		BaseURL: "http://" + addr,
+		RetryPolicy: RetryPolicy{
+			MaxAttempts: 3,
+			BaseBackoff: time.Millisecond,
+			OnRetry:     func(e RetryEvent) { events = append(events, e) },
+		},
+	}).(client)
+
+	_, err = c.getJSONRequestResponse(context.Background(), c.cfg.BaseURL, http.MethodPost, map[string]string{"a": "b"})
+
+	var opErr *net.OpError
+	assert.ErrorAs(t, err, &opErr)
+	assert.Len(t, events, 2)
+}
+
+func TestGetRequestResponseRetriesClosedConnection(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// close the connection without answering, as a server
+			// dropping an idle keep-alive connection would
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		BaseURL:     server.URL,
+		RetryPolicy: RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+	}).(client)
+
+	r, err := c.getRequestResponse(context.Background(), server.URL, http.MethodGet, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok": true}`, string(r))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestIsTransientError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "timeout", err: ErrRequestTimeout, expected: true},
+		{name: "refused connection", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, expected: true},
+		{name: "closed connection", err: &url.Error{Op: "Post", Err: io.EOF}, expected: true},
+		{name: "truncated response", err: &url.Error{Op: "Get", Err: io.ErrUnexpectedEOF}, expected: true},
+		{name: "reset connection", err: fmt.Errorf("read: %w", syscall.ECONNRESET), expected: true},
+		{name: "broken pipe", err: fmt.Errorf("write: %w", syscall.EPIPE), expected: true},
+		{name: "canceled", err: ErrRequestCanceled},
+		{name: "invalid url", err: &url.Error{Op: "Get", Err: errors.New("unsupported protocol scheme")}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isTransientError(tc.err))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package gopenai
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	sseFieldData  = "data"
+	sseFieldEvent = "event"
+	sseFieldID    = "id"
+)
+
+// sseEvent is a single event of a server-sent events stream.
+type sseEvent struct {
+	// Event is the event type, empty for the default "message" type.
+	Event string
+	// Data is the event payload. Multiple data fields
+	// of the same event are joined with newlines.
+	Data string
+	// ID is the event ID, if set.
+	ID string
+}
+
+// sseReader reads events from a server-sent events stream
+// as described in the HTML Living Standard.
+type sseReader struct {
+	r *bufio.Reader
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	return &sseReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next event in the stream. It returns
+// io.EOF when the stream ends before another event is complete.
+func (r *sseReader) next() (sseEvent, error) {
+	var (
+		event   sseEvent
+		data    []string
+		hasData bool
+	)
+
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			// a trailing event that is not terminated by a blank
+			// line is still dispatched to be lenient with servers
+			if errors.Is(err, io.EOF) && hasData {
+				event.Data = strings.Join(data, "\n")
+
+				return event, nil
+			}
+
+			return sseEvent{}, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !hasData {
+				event = sseEvent{}
+
+				continue
+			}
+
+			event.Data = strings.Join(data, "\n")
+
+			return event, nil
+		}
+
+		// lines starting with a colon are comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case sseFieldData:
+			data = append(data, value)
+			hasData = true
+		case sseFieldEvent:
+			event.Event = value
+		case sseFieldID:
+			event.ID = value
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gopenai
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEReader(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          string
+		expectedEvents []sseEvent
+	}{
+		{
+			name:  "single line data",
+			input: "data: {\"a\": 1}\n\ndata: [DONE]\n\n",
+			expectedEvents: []sseEvent{
+				{Data: `{"a": 1}`},
+				{Data: "[DONE]"},
+			},
+		},
+		{
+			name:  "multi-line data, comments and crlf",
+			input: ": keep-alive\r\nevent: message\r\nid: 7\r\ndata: first\r\ndata:second\r\n\r\n",
+			expectedEvents: []sseEvent{
+				{Event: "message", ID: "7", Data: "first\nsecond"},
+			},
+		},
+		{
+			name:  "events without data are skipped",
+			input: "event: ping\n\n\n\ndata: x\n\n",
+			expectedEvents: []sseEvent{
+				{Data: "x"},
+			},
+		},
+		{
+			name:  "unterminated trailing event",
+			input: "data: x\n\ndata: y",
+			expectedEvents: []sseEvent{
+				{Data: "x"},
+				{Data: "y"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newSSEReader(strings.NewReader(tc.input))
+
+			var events []sseEvent
+			for {
+				event, err := r.next()
+				if err == io.EOF {
+					break
+				}
+
+				require.NoError(t, err)
+				events = append(events, event)
+			}
+
+			assert.Equal(t, tc.expectedEvents, events)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	sseEventError     = "error"
+	streamDoneMessage = "[DONE]"
+)
+
+// streamReader decodes the JSON payloads of a server-sent
+// events stream into values of type T.
+type streamReader[T any] struct {
+	ctx    context.Context
+	resp   *http.Response
+	events *sseReader
+	done   bool
+}
+
+// recv returns the next value in the stream. It returns io.EOF once
+// the stream has ended and an *APIError for stream error events.
+func (s *streamReader[T]) recv() (T, error) {
+	var v T
+	if s.done {
+		return v, io.EOF
+	}
+
+	event, err := s.events.next()
+	if err != nil {
+		s.done = true
+		if err == io.EOF {
+			return v, io.EOF
+		}
+
+		return v, requestError(s.ctx, err)
+	}
+
+	if event.Data == streamDoneMessage {
+		s.done = true
+
+		return v, io.EOF
+	}
+
+	if apiErr := s.eventError(event); apiErr != nil {
+		s.done = true
+
+		return v, apiErr
+	}
+
+	if err := json.Unmarshal([]byte(event.Data), &v); err != nil {
+		return v, err
+	}
+
+	return v, nil
+}
+
+// eventError returns the error carried by an error event or by
+// an event with an error object as its payload, if any.
+func (s *streamReader[T]) eventError(event sseEvent) *APIError {
+	var payload struct {
+		Error *apiErrorObject `json:"error"`
+	}
+
+	_ = json.Unmarshal([]byte(event.Data), &payload)
+	if payload.Error == nil && event.Event != sseEventError {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: s.resp.StatusCode,
+		Message:    event.Data,
+		RequestID:  s.resp.Header.Get(headerNameRequestID),
+		Header:     s.resp.Header,
+		Body:       []byte(event.Data),
+	}
+
+	if payload.Error != nil {
+		payload.Error.apply(apiErr)
+	}
+
+	return apiErr
+}
+
+func (s *streamReader[T]) close() error {
+	return s.resp.Body.Close()
+}
+
+// getStreamResponse sends a request expecting a server-sent events
+// response and returns a reader for the events in the response.
+// A nil data is sent as an empty body.
+func getStreamResponse[T any](ctx context.Context, c client, reqURL, method string, data interface{}) (*streamReader[T], error) {
+	var (
+		body        bodyFunc
+		contentType string
+	)
+
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+
+		body, contentType = bytesBody(jsonData), contentTypeJSON
+	}
+
+	c = c.streaming()
+
+	resp, err := c.getHTTPResponse(ctx, reqURL, method, nil, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+
+		return nil, newAPIError(resp)
+	}
+
+	return &streamReader[T]{
+		ctx:    ctx,
+		resp:   resp,
+		events: newSSEReader(resp.Body),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+const (
+	defaultStructuredOutputName = "response"
+	maxStructuredOutputNameLen  = 64
+	finishReasonLength          = "length"
+
+	structuredOutputRetryPromptTpl = "Your previous response did not match the required JSON Schema: %s. " +
+		"Reply again with only a JSON value that matches the schema."
+)
+
+var invalidStructuredOutputNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// StructuredOutputOptions configures CreateStructured.
+type StructuredOutputOptions struct {
+	// Name is the name of the response format. Defaults to
+	// the name of the type the response is decoded into.
+	Name string
+	// Description describes the response format to the model.
+	Description string
+	// MaxRetries is the number of times the model is asked again,
+	// with the validation error appended to the conversation, when
+	// its response does not match the schema.
+	MaxRetries int
+}
+
+// CreateStructured creates a chat completion whose response is a JSON
+// object matching the JSON Schema generated from T and decodes it. The
+// schema is sent in strict mode, so T must be a struct, or a pointer to
+// one, that only uses features strict mode supports; other types fail
+// with ErrUnsupportedStructuredOutput before any request is sent. It
+// returns a *RefusalError when the model refuses to answer,
+// ErrResponseTruncated when the response was cut off by the token limit
+// and a *JSONSchemaValidationError when the response does not match the
+// schema after all retries. The returned ChatCompletion is the last one
+// received, even on error.
+func CreateStructured[T any](ctx context.Context, api ChatCompletionsAPI, params ChatCompletionParams, opts StructuredOutputOptions) (T, ChatCompletion, error) {
+	var result T
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	schema, err := generateRootJSONSchema(t)
+	if err != nil {
+		return result, ChatCompletion{}, err
+	}
+
+	strictSchema, strict := schema.Strict()
+	if !strict || schema.Type != JSONSchemaTypeObject || schema.Nullable {
+		return result, ChatCompletion{}, fmt.Errorf("%w: %s", ErrUnsupportedStructuredOutput, t)
+	}
+
+	schema = strictSchema
+
+	params.ResponseFormat = &ChatCompletionResponseFormat{
+		Type: ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &ChatCompletionResponseFormatJSONSchema{
+			Name:        structuredOutputName(opts.Name, t),
+			Description: opts.Description,
+			Schema:      schema,
+			Strict:      true,
+		},
+	}
+
+	// copy the messages so the retry prompts do not leak to the caller
+	params.Messages = append([]ChatCompletionMessage(nil), params.Messages...)
+
+	for attempt := 0; ; attempt++ {
+		completion, err := api.CreateWithContext(ctx, params)
+		if err != nil {
+			return result, completion, err
+		}
+
+		if len(completion.Choices) == 0 {
+			return result, completion, ErrNoChoices
+		}
+
+		choice := completion.Choices[0]
+		if choice.Message.Refusal != "" {
+			return result, completion, &RefusalError{Refusal: choice.Message.Refusal}
+		}
+
+		if choice.FinishReason == finishReasonLength {
+			return result, completion, ErrResponseTruncated
+		}
+
+		err = schema.Validate([]byte(choice.Message.Content))
+		if err == nil {
+			err = json.Unmarshal([]byte(choice.Message.Content), &result)
+		}
+
+		if err == nil || attempt >= opts.MaxRetries {
+			return result, completion, err
+		}
+
+		params.Messages = append(params.Messages, choice.Message, ChatCompletionMessage{
+			Role:    ChatCompletionMessageRoleUser,
+			Content: fmt.Sprintf(structuredOutputRetryPromptTpl, err),
+		})
+	}
+}
+
+func structuredOutputName(name string, t reflect.Type) string {
+	if name == "" {
+		name = t.Name()
+	}
+
+	name = invalidStructuredOutputNameChars.ReplaceAllString(name, "_")
+	if name == "" {
+		name = defaultStructuredOutputName
+	}
+
+	if len(name) > maxStructuredOutputNameLen {
+		name = name[:maxStructuredOutputNameLen]
+	}
+
+	return name
+}
@@ -0,0 +1,204 @@
+package gopenai
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChatCompletionsAPI returns the given responses in order. It is safe
+// for concurrent use and, when started is set, reports each request on
+// it and holds the response until release is closed.
+type fakeChatCompletionsAPI struct {
+	mu        sync.Mutex
+	responses []ChatCompletion
+	requests  []ChatCompletionParams
+	started   chan struct{}
+	release   chan struct{}
+}
+
+func (api *fakeChatCompletionsAPI) Create(params ChatCompletionParams) (ChatCompletion, error) {
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api *fakeChatCompletionsAPI) CreateWithContext(_ context.Context, params ChatCompletionParams) (ChatCompletion, error) {
+	api.mu.Lock()
+	api.requests = append(api.requests, params)
+	response := api.responses[0]
+	api.responses = api.responses[1:]
+	api.mu.Unlock()
+
+	if api.started != nil {
+		api.started <- struct{}{}
+		<-api.release
+	}
+
+	return response, nil
+}
+
+func (api *fakeChatCompletionsAPI) CreateStream(context.Context, ChatCompletionParams) (*ChatCompletionStream, error) {
+	return nil, nil
+}
+
+func newTestChatCompletion(message ChatCompletionMessage, finishReason string) ChatCompletion {
+	message.Role = ChatCompletionMessageRoleAssistant
+
+	return ChatCompletion{
+		Choices: []ChatCompletionChoice{{Message: message, FinishReason: finishReason}},
+	}
+}
+
+type testRecipe struct {
+	Title       string   `json:"title"`
+	Ingredients []string `json:"ingredients"`
+	Minutes     int      `json:"minutes,omitempty"`
+}
+
+func TestCreateStructured(t *testing.T) {
+	testCases := []struct {
+		name          string
+		responses     []ChatCompletion
+		maxRetries    int
+		expected      testRecipe
+		expectedCalls int
+		expectedErr   error
+		expectedErrAs interface{}
+	}{
+		{
+			name: "valid response",
+			responses: []ChatCompletion{
+				newTestChatCompletion(ChatCompletionMessage{
+					Content: `{"title": "Soup", "ingredients": ["water"], "minutes": null}`,
+				}, "stop"),
+			},
+			expected:      testRecipe{Title: "Soup", Ingredients: []string{"water"}},
+			expectedCalls: 1,
+		},
+		{
+			name:       "invalid response retried",
+			maxRetries: 1,
+			responses: []ChatCompletion{
+				newTestChatCompletion(ChatCompletionMessage{Content: `{"title": "Soup"}`}, "stop"),
+				newTestChatCompletion(ChatCompletionMessage{
+					Content: `{"title": "Soup", "ingredients": [], "minutes": 5}`,
+				}, "stop"),
+			},
+			expected:      testRecipe{Title: "Soup", Ingredients: []string{}, Minutes: 5},
+			expectedCalls: 2,
+		},
+		{
+			name: "invalid response without retries",
+			responses: []ChatCompletion{
+				newTestChatCompletion(ChatCompletionMessage{Content: `{"title": "Soup"}`}, "stop"),
+			},
+			expectedCalls: 1,
+			expectedErrAs: new(*JSONSchemaValidationError),
+		},
+		{
+			name: "refusal",
+			responses: []ChatCompletion{
+				newTestChatCompletion(ChatCompletionMessage{Refusal: "I can't help with that."}, "stop"),
+			},
+			expectedCalls: 1,
+			expectedErrAs: new(*RefusalError),
+		},
+		{
+			name:       "truncated",
+			maxRetries: 3,
+			responses: []ChatCompletion{
+				newTestChatCompletion(ChatCompletionMessage{Content: `{"title": "So`}, "length"),
+			},
+			expectedCalls: 1,
+			expectedErr:   ErrResponseTruncated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := &fakeChatCompletionsAPI{responses: tc.responses}
+			params := ChatCompletionParams{
+				Model:    "gpt-4o",
+				Messages: []ChatCompletionMessage{{Role: ChatCompletionMessageRoleUser, Content: "A recipe"}},
+			}
+
+			recipe, _, err := CreateStructured[testRecipe](context.Background(), api, params,
+				StructuredOutputOptions{MaxRetries: tc.maxRetries})
+
+			assert.Len(t, api.requests, tc.expectedCalls)
+			assert.Len(t, params.Messages, 1)
+
+			switch {
+			case tc.expectedErr != nil:
+				assert.ErrorIs(t, err, tc.expectedErr)
+			case tc.expectedErrAs != nil:
+				assert.ErrorAs(t, err, tc.expectedErrAs)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, recipe)
+			}
+
+			if tc.expectedCalls > 1 {
+				retryMessages := api.requests[1].Messages
+				require.Len(t, retryMessages, 3)
+				assert.Equal(t, ChatCompletionMessageRoleAssistant, retryMessages[1].Role)
+				assert.Contains(t, retryMessages[2].Content, "$.ingredients: is required")
+			}
+		})
+	}
+}
+
+func TestCreateStructuredResponseFormat(t *testing.T) {
+	api := &fakeChatCompletionsAPI{responses: []ChatCompletion{
+		newTestChatCompletion(ChatCompletionMessage{
+			Content: `{"title": "Soup", "ingredients": [], "minutes": null}`,
+		}, "stop"),
+	}}
+
+	_, _, err := CreateStructured[testRecipe](context.Background(), api, ChatCompletionParams{}, StructuredOutputOptions{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(api.requests[0].ResponseFormat)
+	require.NoError(t, err)
+
+	expected := `{
+		"type": "json_schema",
+		"json_schema": {
+			"name": "testRecipe",
+			"strict": true,
+			"schema": {
+				"type": "object",
+				"properties": {
+					"title": {"type": "string"},
+					"ingredients": {"type": "array", "items": {"type": "string"}},
+					"minutes": {"type": ["integer", "null"]}
+				},
+				"required": ["title", "ingredients", "minutes"],
+				"additionalProperties": false
+			}
+		}
+	}`
+
+	assert.JSONEq(t, expected, string(data))
+}
+
+func TestCreateStructuredUnsupportedType(t *testing.T) {
+	api := &fakeChatCompletionsAPI{}
+
+	_, _, err := CreateStructured[[]testRecipe](context.Background(), api, ChatCompletionParams{}, StructuredOutputOptions{})
+	assert.ErrorIs(t, err, ErrUnsupportedStructuredOutput)
+
+	_, _, err = CreateStructured[string](context.Background(), api, ChatCompletionParams{}, StructuredOutputOptions{})
+	assert.ErrorIs(t, err, ErrUnsupportedStructuredOutput)
+
+	_, _, err = CreateStructured[map[string]int](context.Background(), api, ChatCompletionParams{}, StructuredOutputOptions{})
+	assert.ErrorIs(t, err, ErrUnsupportedStructuredOutput)
+
+	_, _, err = CreateStructured[struct{ Tags map[string]string }](context.Background(), api, ChatCompletionParams{}, StructuredOutputOptions{})
+	assert.ErrorIs(t, err, ErrUnsupportedStructuredOutput)
+
+	assert.Empty(t, api.requests)
+}
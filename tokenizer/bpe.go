@@ -0,0 +1,77 @@
+package tokenizer
+
+import "math"
+
+const maxRank = math.MaxInt
+
+// bytePairEncode returns the tokens of the given piece by repeatedly
+// merging the pair of adjacent parts with the lowest rank until no pair
+// of parts is a token. This is the algorithm used by tiktoken.
+func bytePairEncode(piece []byte, ranks map[string]int) []int {
+	if len(piece) == 1 {
+		return []int{ranks[string(piece)]}
+	}
+
+	type part struct {
+		start int
+		rank  int
+	}
+
+	// parts holds the start of each part and the rank of
+	// the pair formed by the part and the next one
+	parts := make([]part, 0, len(piece)+1)
+	minRank, minIndex := maxRank, -1
+
+	for i := 0; i < len(piece)-1; i++ {
+		rank, ok := ranks[string(piece[i:i+2])]
+		if !ok {
+			rank = maxRank
+		}
+
+		if rank < minRank {
+			minRank, minIndex = rank, i
+		}
+
+		parts = append(parts, part{start: i, rank: rank})
+	}
+
+	parts = append(parts, part{start: len(piece) - 1, rank: maxRank}, part{start: len(piece), rank: maxRank})
+
+	// getRank returns the rank of the pair starting at part i as
+	// it will be once the part after it has been merged into it
+	getRank := func(i int) int {
+		if i+3 >= len(parts) {
+			return maxRank
+		}
+
+		if rank, ok := ranks[string(piece[parts[i].start:parts[i+3].start])]; ok {
+			return rank
+		}
+
+		return maxRank
+	}
+
+	for minRank != maxRank {
+		i := minIndex
+		if i > 0 {
+			parts[i-1].rank = getRank(i - 1)
+		}
+
+		parts[i].rank = getRank(i)
+		parts = append(parts[:i+1], parts[i+2:]...)
+
+		minRank, minIndex = maxRank, -1
+		for j, p := range parts[:len(parts)-1] {
+			if p.rank < minRank {
+				minRank, minIndex = p.rank, j
+			}
+		}
+	}
+
+	tokens := make([]int, 0, len(parts)-1)
+	for i := 0; i < len(parts)-1; i++ {
+		tokens = append(tokens, ranks[string(piece[parts[i].start:parts[i+1].start])])
+	}
+
+	return tokens
+}
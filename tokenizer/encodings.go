@@ -0,0 +1,262 @@
+package tokenizer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:generate curl -sSfo ranks/r50k_base.tiktoken https://openaipublic.blob.core.windows.net/encodings/r50k_base.tiktoken
+//go:generate curl -sSfo ranks/p50k_base.tiktoken https://openaipublic.blob.core.windows.net/encodings/p50k_base.tiktoken
+//go:generate curl -sSfo ranks/cl100k_base.tiktoken https://openaipublic.blob.core.windows.net/encodings/cl100k_base.tiktoken
+//go:generate curl -sSfo ranks/o200k_base.tiktoken https://openaipublic.blob.core.windows.net/encodings/o200k_base.tiktoken
+
+// Encoding names
+const (
+	R50KBase   = "r50k_base"
+	P50KBase   = "p50k_base"
+	P50KEdit   = "p50k_edit"
+	CL100KBase = "cl100k_base"
+	O200KBase  = "o200k_base"
+)
+
+const (
+	endOfText   = "<|endoftext|>"
+	fimPrefix   = "<|fim_prefix|>"
+	fimMiddle   = "<|fim_middle|>"
+	fimSuffix   = "<|fim_suffix|>"
+	endOfPrompt = "<|endofprompt|>"
+
+	ranksDir     = "ranks"
+	ranksFileExt = ".tiktoken"
+)
+
+var (
+	// ErrUnknownEncoding is an error that indicates
+	// there is no encoding with the given name.
+	ErrUnknownEncoding = errors.New("unknown encoding")
+	// ErrUnknownModel is an error that indicates the
+	// encoding used by the given model is not known.
+	ErrUnknownModel = errors.New("unknown model")
+	// ErrRanksNotEmbedded is an error that indicates the mergeable
+	// ranks of an encoding are missing from the package, which
+	// only happens in a tree where the ranks files were removed.
+	ErrRanksNotEmbedded = errors.New("encoding ranks not embedded")
+)
+
+//go:embed ranks
+var ranksFS embed.FS
+
+type encodingSpec struct {
+	ranksFile     string
+	match         pretokenizer
+	specialTokens map[string]int
+}
+
+var encodingSpecs = map[string]encodingSpec{
+	R50KBase: {
+		ranksFile:     R50KBase,
+		match:         matchR50K,
+		specialTokens: map[string]int{endOfText: 50256},
+	},
+	P50KBase: {
+		ranksFile:     P50KBase,
+		match:         matchR50K,
+		specialTokens: map[string]int{endOfText: 50256},
+	},
+	P50KEdit: {
+		ranksFile: P50KBase,
+		match:     matchR50K,
+		specialTokens: map[string]int{
+			endOfText: 50256,
+			fimPrefix: 50281,
+			fimMiddle: 50282,
+			fimSuffix: 50283,
+		},
+	},
+	CL100KBase: {
+		ranksFile: CL100KBase,
+		match:     matchCL100K,
+		specialTokens: map[string]int{
+			endOfText:   100257,
+			fimPrefix:   100258,
+			fimMiddle:   100259,
+			fimSuffix:   100260,
+			endOfPrompt: 100276,
+		},
+	},
+	O200KBase: {
+		ranksFile: O200KBase,
+		match:     matchO200K,
+		specialTokens: map[string]int{
+			endOfText:   199999,
+			endOfPrompt: 200018,
+		},
+	},
+}
+
+var modelEncodings = map[string]string{
+	// chat
+	"gpt-5":             O200KBase,
+	"gpt-4.1":           O200KBase,
+	"gpt-4o":            O200KBase,
+	"chatgpt-4o-latest": O200KBase,
+	"o1":                O200KBase,
+	"o3":                O200KBase,
+	"o4-mini":           O200KBase,
+	"gpt-4":             CL100KBase,
+	"gpt-3.5-turbo":     CL100KBase,
+	"gpt-3.5":           CL100KBase,
+	"gpt-35-turbo":      CL100KBase,
+	// base
+	"davinci-002": CL100KBase,
+	"babbage-002": CL100KBase,
+	// embeddings
+	"text-embedding-ada-002": CL100KBase,
+	"text-embedding-3-small": CL100KBase,
+	"text-embedding-3-large": CL100KBase,
+	// legacy completions
+	"text-davinci-003": P50KBase,
+	"text-davinci-002": P50KBase,
+	"text-davinci-001": R50KBase,
+	"text-curie-001":   R50KBase,
+	"text-babbage-001": R50KBase,
+	"text-ada-001":     R50KBase,
+	"davinci":          R50KBase,
+	"curie":            R50KBase,
+	"babbage":          R50KBase,
+	"ada":              R50KBase,
+	// legacy code
+	"code-davinci-002": P50KBase,
+	"code-davinci-001": P50KBase,
+	"code-cushman-002": P50KBase,
+	"code-cushman-001": P50KBase,
+	"davinci-codex":    P50KBase,
+	"cushman-codex":    P50KBase,
+	// legacy edits
+	"text-davinci-edit-001": P50KEdit,
+	"code-davinci-edit-001": P50KEdit,
+	// legacy embeddings
+	"text-similarity-davinci-001":  R50KBase,
+	"text-similarity-curie-001":    R50KBase,
+	"text-similarity-babbage-001":  R50KBase,
+	"text-similarity-ada-001":      R50KBase,
+	"text-search-davinci-doc-001":  R50KBase,
+	"text-search-curie-doc-001":    R50KBase,
+	"text-search-babbage-doc-001":  R50KBase,
+	"text-search-ada-doc-001":      R50KBase,
+	"code-search-babbage-code-001": R50KBase,
+	"code-search-ada-code-001":     R50KBase,
+	// open source
+	"gpt2": R50KBase,
+}
+
+var modelPrefixEncodings = map[string]string{
+	"gpt-5-":           O200KBase,
+	"gpt-4.1-":         O200KBase,
+	"gpt-4o-":          O200KBase,
+	"chatgpt-4o-":      O200KBase,
+	"o1-":              O200KBase,
+	"o3-":              O200KBase,
+	"o4-mini-":         O200KBase,
+	"gpt-4-":           CL100KBase,
+	"gpt-3.5-turbo-":   CL100KBase,
+	"gpt-35-turbo-":    CL100KBase,
+	"ft:gpt-4o":        O200KBase,
+	"ft:gpt-4.1":       O200KBase,
+	"ft:gpt-4":         CL100KBase,
+	"ft:gpt-3.5-turbo": CL100KBase,
+	"ft:davinci-002":   CL100KBase,
+	"ft:babbage-002":   CL100KBase,
+}
+
+// modelPrefixes holds the keys of modelPrefixEncodings,
+// longest first so that the most specific prefix wins.
+var modelPrefixes = func() []string {
+	prefixes := make([]string, 0, len(modelPrefixEncodings))
+	for prefix := range modelPrefixEncodings {
+		prefixes = append(prefixes, prefix)
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+
+		return prefixes[i] < prefixes[j]
+	})
+
+	return prefixes
+}()
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = map[string]*Encoding{}
+)
+
+// GetEncoding returns the encoding with the given name,
+// loading its embedded mergeable ranks on first use.
+func GetEncoding(name string) (*Encoding, error) {
+	spec, ok := encodingSpecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+	}
+
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+
+	if encoding, ok := encodings[name]; ok {
+		return encoding, nil
+	}
+
+	file, err := ranksFS.Open(path.Join(ranksDir, spec.ranksFile+ranksFileExt))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrRanksNotEmbedded, name)
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	ranks, err := parseRanks(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the ranks of encoding %s: %w", name, err)
+	}
+
+	encoding := newEncoding(name, spec.match, ranks, spec.specialTokens)
+	encodings[name] = encoding
+
+	return encoding, nil
+}
+
+// EncodingNameForModel returns the name of the encoding used by the
+// given model. Fine-tuned models and dated snapshots are supported.
+func EncodingNameForModel(model string) (string, error) {
+	if name, ok := modelEncodings[model]; ok {
+		return name, nil
+	}
+
+	for _, prefix := range modelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return modelPrefixEncodings[prefix], nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownModel, model)
+}
+
+// EncodingForModel returns the encoding used by the given model.
+func EncodingForModel(model string) (*Encoding, error) {
+	name, err := EncodingNameForModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetEncoding(name)
+}
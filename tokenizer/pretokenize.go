@@ -0,0 +1,301 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A pretokenizer splits text into the pieces that are byte pair
+// encoded independently. The pretokenizers below are hand written
+// equivalents of the regular expressions used by tiktoken, which
+// rely on lookahead that the regexp package does not support.
+type pretokenizer func(text string, i int) int
+
+var contractions = []string{"s", "t", "re", "ve", "m", "ll", "d"}
+
+// split calls yield with each piece of text, as matched by match.
+func split(text string, match pretokenizer, yield func(piece string)) {
+	for i := 0; i < len(text); {
+		end := match(text, i)
+		if end <= i {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			end = i + size
+		}
+
+		yield(text[i:end])
+		i = end
+	}
+}
+
+// matchR50K matches the pattern of the r50k and p50k encodings:
+//
+//	's|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+
+func matchR50K(s string, i int) int {
+	if end := matchContraction(s, i, false); end > 0 {
+		return end
+	}
+
+	j := i
+	if s[j] == ' ' {
+		j++
+	}
+
+	if r, size := nextRune(s, j); size > 0 {
+		switch {
+		case isLetter(r):
+			return scan(s, j, isLetter)
+		case isNumber(r):
+			return scan(s, j, isNumber)
+		case isOther(r):
+			return scan(s, j, isOther)
+		}
+	}
+
+	return matchWhitespace(s, i)
+}
+
+// matchCL100K matches the pattern of the cl100k encoding:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func matchCL100K(s string, i int) int {
+	if end := matchContraction(s, i, true); end > 0 {
+		return end
+	}
+
+	r, size := nextRune(s, i)
+	if isLetter(r) {
+		return scan(s, i, isLetter)
+	}
+
+	if isLetterPrefix(r) {
+		if next, nextSize := nextRune(s, i+size); nextSize > 0 && isLetter(next) {
+			return scan(s, i+size, isLetter)
+		}
+	}
+
+	if isNumber(r) {
+		return matchDigits(s, i)
+	}
+
+	if end := matchPunctuation(s, i, isNewline); end > 0 {
+		return end
+	}
+
+	if end := matchNewlines(s, i); end > 0 {
+		return end
+	}
+
+	return matchWhitespace(s, i)
+}
+
+// matchO200K matches the pattern of the o200k encoding:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func matchO200K(s string, i int) int {
+	r, size := nextRune(s, i)
+
+	for _, matchWord := range []pretokenizer{matchLowerWord, matchUpperWord} {
+		if isLetterPrefix(r) {
+			if end := matchWord(s, i+size); end > 0 {
+				return end
+			}
+		}
+
+		if end := matchWord(s, i); end > 0 {
+			return end
+		}
+	}
+
+	if isNumber(r) {
+		return matchDigits(s, i)
+	}
+
+	if end := matchPunctuation(s, i, func(r rune) bool { return isNewline(r) || r == '/' }); end > 0 {
+		return end
+	}
+
+	if end := matchNewlines(s, i); end > 0 {
+		return end
+	}
+
+	return matchWhitespace(s, i)
+}
+
+// matchLowerWord matches [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+
+// followed by an optional contraction.
+func matchLowerWord(s string, i int) int {
+	upperEnd := scan(s, i, isUpperish)
+	if r, size := nextRune(s, upperEnd); size > 0 && isLowerish(r) {
+		return matchOptionalContraction(s, scan(s, upperEnd, isLowerish))
+	}
+
+	// backtrack into the upper case run, looking for a
+	// rune that also belongs to the lower case class
+	for j := upperEnd; j > i; {
+		r, size := utf8.DecodeLastRuneInString(s[i:j])
+		j -= size
+
+		if isLowerish(r) {
+			return matchOptionalContraction(s, scan(s, j, isLowerish))
+		}
+	}
+
+	return -1
+}
+
+// matchUpperWord matches [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*
+// followed by an optional contraction.
+func matchUpperWord(s string, i int) int {
+	upperEnd := scan(s, i, isUpperish)
+	if upperEnd == i {
+		return -1
+	}
+
+	return matchOptionalContraction(s, scan(s, upperEnd, isLowerish))
+}
+
+// matchContraction matches 's, 't, 're, 've, 'm, 'll or 'd.
+func matchContraction(s string, i int, ignoreCase bool) int {
+	if i >= len(s) || s[i] != '\'' {
+		return -1
+	}
+
+	for _, contraction := range contractions {
+		end := i + 1 + len(contraction)
+		if end > len(s) {
+			continue
+		}
+
+		candidate := s[i+1 : end]
+		if candidate == contraction || (ignoreCase && strings.ToLower(candidate) == contraction) {
+			return end
+		}
+	}
+
+	return -1
+}
+
+func matchOptionalContraction(s string, i int) int {
+	if end := matchContraction(s, i, true); end > 0 {
+		return end
+	}
+
+	return i
+}
+
+// matchDigits matches \p{N}{1,3}.
+func matchDigits(s string, i int) int {
+	end := i
+	for n := 0; n < 3; n++ {
+		r, size := nextRune(s, end)
+		if size == 0 || !isNumber(r) {
+			break
+		}
+
+		end += size
+	}
+
+	return end
+}
+
+// matchPunctuation matches ` ?[^\s\p{L}\p{N}]+` followed
+// by any number of runes matching trailing.
+func matchPunctuation(s string, i int, trailing func(rune) bool) int {
+	j := i
+	if s[j] == ' ' {
+		j++
+	}
+
+	if r, size := nextRune(s, j); size == 0 || !isOther(r) {
+		return -1
+	}
+
+	return scan(s, scan(s, j, isOther), trailing)
+}
+
+// matchNewlines matches \s*[\r\n]+.
+func matchNewlines(s string, i int) int {
+	end := scan(s, i, unicode.IsSpace)
+
+	last := strings.LastIndexAny(s[i:end], "\r\n")
+	if last < 0 {
+		return -1
+	}
+
+	return i + last + 1
+}
+
+// matchWhitespace matches \s+(?!\S)|\s+, so that a run of whitespace
+// followed by a non-whitespace rune leaves its last rune to the
+// piece that follows, like " world" in "hello   world".
+func matchWhitespace(s string, i int) int {
+	end := scan(s, i, unicode.IsSpace)
+	if end == i || end == len(s) {
+		return end
+	}
+
+	_, size := utf8.DecodeLastRuneInString(s[i:end])
+	if end-size > i {
+		return end - size
+	}
+
+	return end
+}
+
+// scan returns the index of the first rune from i not matching f.
+func scan(s string, i int, f func(rune) bool) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !f(r) {
+			break
+		}
+
+		i += size
+	}
+
+	return i
+}
+
+// nextRune returns the rune at i and its size,
+// which is zero if i is at the end of s.
+func nextRune(s string, i int) (rune, int) {
+	if i >= len(s) {
+		return utf8.RuneError, 0
+	}
+
+	return utf8.DecodeRuneInString(s[i:])
+}
+
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r)
+}
+
+func isNumber(r rune) bool {
+	return unicode.IsNumber(r)
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+// isOther reports whether r matches [^\s\p{L}\p{N}].
+func isOther(r rune) bool {
+	return !unicode.IsSpace(r) && !isLetter(r) && !isNumber(r)
+}
+
+// isLetterPrefix reports whether r matches [^\r\n\p{L}\p{N}].
+func isLetterPrefix(r rune) bool {
+	return !isNewline(r) && !isLetter(r) && !isNumber(r)
+}
+
+func isUpperish(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isLowerish(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
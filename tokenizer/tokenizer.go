@@ -0,0 +1,194 @@
+// Package tokenizer implements the byte pair encodings used by OpenAI
+// models, for counting the tokens of a text without calling the API.
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownToken is an error that indicates a token
+// does not belong to the encoding it is decoded with.
+var ErrUnknownToken = errors.New("unknown token")
+
+// Encoding is a byte pair encoding. It is safe for concurrent use.
+type Encoding struct {
+	name          string
+	match         pretokenizer
+	ranks         map[string]int
+	specialTokens map[string]int
+	decoder       map[int]string
+}
+
+// NewEncoding returns the encoding with the given name using the mergeable
+// ranks read from r, in the tiktoken format: one base64 encoded token
+// and its rank per line. It can be used to load the ranks of an encoding
+// from a file when they are not embedded in the package.
+func NewEncoding(name string, r io.Reader) (*Encoding, error) {
+	spec, ok := encodingSpecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+	}
+
+	ranks, err := parseRanks(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the ranks of encoding %s: %w", name, err)
+	}
+
+	return newEncoding(name, spec.match, ranks, spec.specialTokens), nil
+}
+
+func newEncoding(name string, match pretokenizer, ranks map[string]int, specialTokens map[string]int) *Encoding {
+	decoder := make(map[int]string, len(ranks)+len(specialTokens))
+	for token, rank := range ranks {
+		decoder[rank] = token
+	}
+
+	for token, rank := range specialTokens {
+		decoder[rank] = token
+	}
+
+	return &Encoding{
+		name:          name,
+		match:         match,
+		ranks:         ranks,
+		specialTokens: specialTokens,
+		decoder:       decoder,
+	}
+}
+
+// Name returns the name of the encoding, like cl100k_base.
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// Encode returns the tokens of the given text. Special tokens
+// like <|endoftext|> are encoded as ordinary text.
+func (e *Encoding) Encode(text string) []int {
+	tokens := []int{}
+	e.encode(text, func(token int) {
+		tokens = append(tokens, token)
+	})
+
+	return tokens
+}
+
+// EncodeWithSpecialTokens returns the tokens of the given text,
+// encoding special tokens like <|endoftext|> as their own token.
+func (e *Encoding) EncodeWithSpecialTokens(text string) []int {
+	tokens := []int{}
+	for text != "" {
+		start, special := e.nextSpecialToken(text)
+		e.encode(text[:start], func(token int) {
+			tokens = append(tokens, token)
+		})
+
+		if special == "" {
+			break
+		}
+
+		tokens = append(tokens, e.specialTokens[special])
+		text = text[start+len(special):]
+	}
+
+	return tokens
+}
+
+// Count returns the number of tokens of the given
+// text, encoding special tokens as ordinary text.
+func (e *Encoding) Count(text string) int {
+	count := 0
+	e.encode(text, func(int) {
+		count++
+	})
+
+	return count
+}
+
+// Decode returns the text of the given tokens. It returns
+// ErrUnknownToken if a token does not belong to the encoding.
+func (e *Encoding) Decode(tokens []int) (string, error) {
+	b := strings.Builder{}
+	for _, token := range tokens {
+		piece, ok := e.decoder[token]
+		if !ok {
+			return "", fmt.Errorf("%w: %d", ErrUnknownToken, token)
+		}
+
+		b.WriteString(piece)
+	}
+
+	return b.String(), nil
+}
+
+func (e *Encoding) encode(text string, yield func(token int)) {
+	split(text, e.match, func(piece string) {
+		if rank, ok := e.ranks[piece]; ok {
+			yield(rank)
+
+			return
+		}
+
+		for _, token := range bytePairEncode([]byte(piece), e.ranks) {
+			yield(token)
+		}
+	})
+}
+
+// nextSpecialToken returns the first special token in text and its
+// index, or the length of text and an empty string if there is none.
+func (e *Encoding) nextSpecialToken(text string) (int, string) {
+	start, special := len(text), ""
+	for token := range e.specialTokens {
+		i := strings.Index(text, token)
+		if i < 0 {
+			continue
+		}
+
+		if i < start || (i == start && len(token) > len(special)) {
+			start, special = i, token
+		}
+	}
+
+	return start, special
+}
+
+// parseRanks parses mergeable ranks in the tiktoken format.
+func parseRanks(r io.Reader) (map[string]int, error) {
+	ranks := map[string]int{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line %d", line)
+		}
+
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid token on line %d: %w", line, err)
+		}
+
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank on line %d: %w", line, err)
+		}
+
+		ranks[string(token)] = rank
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ranks, nil
+}
@@ -0,0 +1,317 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRanks returns the ranks of a tiny encoding: every byte
+// plus the merges needed to encode "hello world" in three tokens.
+func newTestRanks() map[string]int {
+	ranks := map[string]int{}
+	for i := 0; i < 256; i++ {
+		ranks[string([]byte{byte(i)})] = i
+	}
+
+	for i, merge := range []string{"ll", "he", "hell", " w", "or", " wor", "ld", " world"} {
+		ranks[merge] = 256 + i
+	}
+
+	return ranks
+}
+
+func newTestEncoding() *Encoding {
+	return newEncoding("test", matchR50K, newTestRanks(), map[string]int{endOfText: 300})
+}
+
+func newTestRanksFile(ranks map[string]int) string {
+	tokens := make([]string, 0, len(ranks))
+	for token := range ranks {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return ranks[tokens[i]] < ranks[tokens[j]] })
+
+	b := strings.Builder{}
+	for _, token := range tokens {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), ranks[token])
+	}
+
+	return b.String()
+}
+
+// getEmbeddedEncoding returns the encoding with the given name,
+// failing the test if its ranks are not embedded.
+func getEmbeddedEncoding(tb testing.TB, name string) *Encoding {
+	tb.Helper()
+
+	encoding, err := GetEncoding(name)
+	require.NoError(tb, err)
+
+	return encoding
+}
+
+func TestEncodingEncode(t *testing.T) {
+	encoding := newTestEncoding()
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []int
+	}{
+		{
+			name:     "empty",
+			text:     "",
+			expected: []int{},
+		},
+		{
+			name:     "merged",
+			text:     "hello world",
+			expected: []int{258, 'o', 263},
+		},
+		{
+			name:     "unmerged",
+			text:     "hi",
+			expected: []int{'h', 'i'},
+		},
+		{
+			name:     "multi-byte",
+			text:     "é",
+			expected: []int{0xc3, 0xa9},
+		},
+		{
+			name:     "special token as text",
+			text:     "<|endoftext|>",
+			expected: []int{'<', '|', 'e', 'n', 'd', 'o', 'f', 't', 'e', 'x', 't', '|', '>'},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := encoding.Encode(tc.text)
+			assert.Equal(t, tc.expected, tokens)
+			assert.Equal(t, len(tc.expected), encoding.Count(tc.text))
+
+			text, err := encoding.Decode(tokens)
+			require.NoError(t, err)
+			assert.Equal(t, tc.text, text)
+		})
+	}
+}
+
+func TestEncodingEncodeWithSpecialTokens(t *testing.T) {
+	encoding := newTestEncoding()
+
+	tokens := encoding.EncodeWithSpecialTokens("hello<|endoftext|> world<|endoftext|>")
+	assert.Equal(t, []int{258, 'o', 300, 263, 300}, tokens)
+
+	text, err := encoding.Decode(tokens)
+	require.NoError(t, err)
+	assert.Equal(t, "hello<|endoftext|> world<|endoftext|>", text)
+}
+
+func TestEncodingDecodeUnknownToken(t *testing.T) {
+	_, err := newTestEncoding().Decode([]int{1, 1000})
+	assert.ErrorIs(t, err, ErrUnknownToken)
+}
+
+func TestNewEncoding(t *testing.T) {
+	encoding, err := NewEncoding(R50KBase, strings.NewReader(newTestRanksFile(newTestRanks())))
+	require.NoError(t, err)
+	assert.Equal(t, R50KBase, encoding.Name())
+	assert.Equal(t, []int{258, 'o', 263}, encoding.Encode("hello world"))
+	assert.Equal(t, []int{50256}, encoding.EncodeWithSpecialTokens(endOfText))
+
+	_, err = NewEncoding("unknown", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+
+	_, err = NewEncoding(R50KBase, strings.NewReader("aGk= 1 2\n"))
+	assert.Error(t, err)
+
+	_, err = NewEncoding(R50KBase, strings.NewReader("!!! 1\n"))
+	assert.Error(t, err)
+}
+
+func TestPretokenizers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		match    pretokenizer
+		text     string
+		expected []string
+	}{
+		{
+			name:     "r50k",
+			match:    matchR50K,
+			text:     "Hello, world! It's  2024   ok\n",
+			expected: []string{"Hello", ",", " world", "!", " It", "'s", " ", " 2024", "  ", " ok", "\n"},
+		},
+		{
+			name:     "cl100k",
+			match:    matchCL100K,
+			text:     "Hello, WORLD'S 12345 tokens!!\n\n  x",
+			expected: []string{"Hello", ",", " WORLD", "'S", " ", "123", "45", " tokens", "!!\n\n", " ", " x"},
+		},
+		{
+			name:     "cl100k newlines",
+			match:    matchCL100K,
+			text:     "a \n b",
+			expected: []string{"a", " \n", " b"},
+		},
+		{
+			name:     "o200k",
+			match:    matchO200K,
+			text:     "HelloWorld's don't CamelCASE path/to 12345",
+			expected: []string{"Hello", "World's", " don't", " Camel", "CASE", " path", "/to", " ", "123", "45"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pieces := []string{}
+			split(tc.text, tc.match, func(piece string) {
+				pieces = append(pieces, piece)
+			})
+
+			assert.Equal(t, tc.expected, pieces)
+		})
+	}
+}
+
+func TestEncodingNameForModel(t *testing.T) {
+	testCases := []struct {
+		model       string
+		expected    string
+		expectedErr error
+	}{
+		{model: "gpt-4o", expected: O200KBase},
+		{model: "gpt-4o-mini-2024-07-18", expected: O200KBase},
+		{model: "ft:gpt-4o-mini-2024-07-18:org::abc123", expected: O200KBase},
+		{model: "gpt-4-0613", expected: CL100KBase},
+		{model: "ft:gpt-3.5-turbo-0613:org::abc123", expected: CL100KBase},
+		{model: "text-embedding-3-small", expected: CL100KBase},
+		{model: "text-davinci-003", expected: P50KBase},
+		{model: "text-davinci-edit-001", expected: P50KEdit},
+		{model: "davinci", expected: R50KBase},
+		{model: "unknown", expectedErr: ErrUnknownModel},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.model, func(t *testing.T) {
+			name, err := EncodingNameForModel(tc.model)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+}
+
+func TestGetEncodingUnknown(t *testing.T) {
+	_, err := GetEncoding("unknown")
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+}
+
+// TestEmbeddedEncodings checks the encodings against the token
+// IDs tiktoken produces for contractions, digit and whitespace
+// runs, code and non-Latin scripts.
+func TestEmbeddedEncodings(t *testing.T) {
+	testCases := []struct {
+		encoding string
+		text     string
+		expected []int
+	}{
+		{encoding: R50KBase, text: "hello world", expected: []int{31373, 995}},
+		{encoding: R50KBase, text: "tiktoken is great!", expected: []int{83, 1134, 30001, 318, 1049, 0}},
+		{encoding: R50KBase, text: "I'm sure we'll see they're DON'T it'S fine", expected: []int{40, 1101, 1654, 356, 1183, 766, 484, 821, 23917, 6, 51, 340, 6, 50, 3734}},
+		{encoding: R50KBase, text: "1234567 3.14159 2024-10-16", expected: []int{10163, 2231, 3134, 513, 13, 1415, 19707, 48609, 12, 940, 12, 1433}},
+		{encoding: R50KBase, text: "a   b  \n\n  x\t\ty   ", expected: []int{64, 220, 220, 275, 220, 220, 628, 220, 2124, 197, 197, 88, 220, 220, 220}},
+		{encoding: R50KBase, text: "end   \n", expected: []int{437, 220, 220, 220, 198}},
+		{encoding: R50KBase, text: "你好，世界", expected: []int{19526, 254, 25001, 121, 171, 120, 234, 10310, 244, 45911, 234}},
+		{encoding: R50KBase, text: "こんにちは世界", expected: []int{46036, 22174, 28618, 2515, 94, 31676, 10310, 244, 45911, 234}},
+		{encoding: R50KBase, text: "Привет, мир!", expected: []int{140, 253, 21169, 18849, 38857, 16843, 20375, 11, 12466, 120, 18849, 21169, 0}},
+		{encoding: R50KBase, text: "مرحبا بالعالم", expected: []int{25405, 26897, 148, 255, 39848, 12919, 17550, 101, 23525, 44690, 23525, 25405}},
+		{encoding: R50KBase, text: "नमस्ते दुनिया", expected: []int{11976, 101, 11976, 106, 11976, 116, 24231, 235, 11976, 97, 24231, 229, 28225, 99, 24231, 223, 11976, 101, 11976, 123, 11976, 107, 48077}},
+		{encoding: R50KBase, text: "👋🌍 émigré naïve", expected: []int{41840, 233, 8582, 234, 235, 38251, 76, 3692, 2634, 41492}},
+		{encoding: R50KBase, text: "func main() {\n\treturn x+1\n}\n", expected: []int{20786, 1388, 3419, 1391, 198, 197, 7783, 2124, 10, 16, 198, 92, 198}},
+		{encoding: R50KBase, text: "HelloWorld's CamelCASE path/to/file.go", expected: []int{15496, 10603, 338, 43281, 34, 11159, 3108, 14, 1462, 14, 7753, 13, 2188}},
+		{encoding: P50KBase, text: "hello world", expected: []int{31373, 995}},
+		{encoding: P50KBase, text: "tiktoken is great!", expected: []int{83, 1134, 30001, 318, 1049, 0}},
+		{encoding: P50KBase, text: "I'm sure we'll see they're DON'T it'S fine", expected: []int{40, 1101, 1654, 356, 1183, 766, 484, 821, 23917, 6, 51, 340, 6, 50, 3734}},
+		{encoding: P50KBase, text: "1234567 3.14159 2024-10-16", expected: []int{10163, 2231, 3134, 513, 13, 1415, 19707, 48609, 12, 940, 12, 1433}},
+		{encoding: P50KBase, text: "a   b  \n\n  x\t\ty   ", expected: []int{64, 50257, 275, 50257, 628, 220, 2124, 197, 197, 88, 50258}},
+		{encoding: P50KBase, text: "end   \n", expected: []int{437, 50258, 198}},
+		{encoding: P50KBase, text: "你好，世界", expected: []int{19526, 254, 25001, 121, 171, 120, 234, 10310, 244, 45911, 234}},
+		{encoding: P50KBase, text: "こんにちは世界", expected: []int{46036, 22174, 28618, 2515, 94, 31676, 10310, 244, 45911, 234}},
+		{encoding: P50KBase, text: "Привет, мир!", expected: []int{140, 253, 21169, 18849, 38857, 16843, 20375, 11, 12466, 120, 18849, 21169, 0}},
+		{encoding: P50KBase, text: "مرحبا بالعالم", expected: []int{25405, 26897, 148, 255, 39848, 12919, 17550, 101, 23525, 44690, 23525, 25405}},
+		{encoding: P50KBase, text: "नमस्ते दुनिया", expected: []int{11976, 101, 11976, 106, 11976, 116, 24231, 235, 11976, 97, 24231, 229, 28225, 99, 24231, 223, 11976, 101, 11976, 123, 11976, 107, 48077}},
+		{encoding: P50KBase, text: "👋🌍 émigré naïve", expected: []int{41840, 233, 8582, 234, 235, 38251, 76, 3692, 2634, 41492}},
+		{encoding: P50KBase, text: "func main() {\n\treturn x+1\n}\n", expected: []int{20786, 1388, 3419, 1391, 198, 197, 7783, 2124, 10, 16, 198, 92, 198}},
+		{encoding: P50KBase, text: "HelloWorld's CamelCASE path/to/file.go", expected: []int{15496, 10603, 338, 43281, 34, 11159, 3108, 14, 1462, 14, 7753, 13, 2188}},
+		{encoding: CL100KBase, text: "hello world", expected: []int{15339, 1917}},
+		{encoding: CL100KBase, text: "tiktoken is great!", expected: []int{83, 1609, 5963, 374, 2294, 0}},
+		{encoding: CL100KBase, text: "I'm sure we'll see they're DON'T it'S fine", expected: []int{40, 2846, 2771, 584, 3358, 1518, 814, 2351, 45373, 17773, 433, 13575, 7060}},
+		{encoding: CL100KBase, text: "1234567 3.14159 2024-10-16", expected: []int{4513, 10961, 22, 220, 18, 13, 9335, 2946, 220, 2366, 19, 12, 605, 12, 845}},
+		{encoding: CL100KBase, text: "a   b  \n\n  x\t\ty   ", expected: []int{64, 256, 293, 19124, 220, 865, 197, 14862, 262}},
+		{encoding: CL100KBase, text: "end   \n", expected: []int{408, 5996}},
+		{encoding: CL100KBase, text: "你好，世界", expected: []int{57668, 53901, 3922, 3574, 244, 98220}},
+		{encoding: CL100KBase, text: "こんにちは世界", expected: []int{90115, 3574, 244, 98220}},
+		{encoding: CL100KBase, text: "Привет, мир!", expected: []int{54745, 28089, 8341, 11, 11562, 78746, 0}},
+		{encoding: CL100KBase, text: "مرحبا بالعالم", expected: []int{10386, 11318, 30925, 22071, 5821, 28946, 32482, 24102, 32482, 10386}},
+		{encoding: CL100KBase, text: "नमस्ते दुनिया", expected: []int{61196, 88344, 79468, 31584, 97, 35470, 15272, 99, 73753, 61196, 43411, 107, 24810}},
+		{encoding: CL100KBase, text: "👋🌍 émigré naïve", expected: []int{9468, 239, 233, 9468, 234, 235, 4046, 76, 5346, 978, 95980, 588}},
+		{encoding: CL100KBase, text: "func main() {\n\treturn x+1\n}\n", expected: []int{2900, 1925, 368, 341, 862, 865, 10, 16, 198, 534}},
+		{encoding: CL100KBase, text: "HelloWorld's CamelCASE path/to/file.go", expected: []int{9906, 10343, 596, 69254, 41471, 1853, 33529, 24849, 18487}},
+		{encoding: O200KBase, text: "hello world", expected: []int{24912, 2375}},
+		{encoding: O200KBase, text: "tiktoken is great!", expected: []int{83, 8251, 2488, 382, 2212, 0}},
+		{encoding: O200KBase, text: "I'm sure we'll see they're DON'T it'S fine", expected: []int{15390, 3239, 22782, 1921, 18940, 153384, 480, 31233, 8975}},
+		{encoding: O200KBase, text: "1234567 3.14159 2024-10-16", expected: []int{7633, 19354, 22, 220, 18, 13, 16926, 4621, 220, 1323, 19, 12, 702, 12, 1125}},
+		{encoding: O200KBase, text: "a   b  \n\n  x\t\ty   ", expected: []int{64, 256, 287, 11691, 220, 1215, 197, 32013, 271}},
+		{encoding: O200KBase, text: "end   \n", expected: []int{419, 10190}},
+		{encoding: O200KBase, text: "你好，世界", expected: []int{177519, 979, 28428}},
+		{encoding: O200KBase, text: "こんにちは世界", expected: []int{95839, 28428}},
+		{encoding: O200KBase, text: "Привет, мир!", expected: []int{23881, 131903, 11, 37934, 0}},
+		{encoding: O200KBase, text: "مرحبا بالعالم", expected: []int{158894, 26537, 101462, 12773}},
+		{encoding: O200KBase, text: "नमस्ते दुनिया", expected: []int{998, 1637, 14681, 628, 64593}},
+		{encoding: O200KBase, text: "👋🌍 émigré naïve", expected: []int{28823, 233, 64364, 235, 128223, 5213, 377, 153475, 737}},
+		{encoding: O200KBase, text: "func main() {\n\treturn x+1\n}\n", expected: []int{5652, 2758, 416, 405, 1393, 1215, 10, 16, 198, 739}},
+		{encoding: O200KBase, text: "HelloWorld's CamelCASE path/to/file.go", expected: []int{13225, 13046, 885, 112127, 76384, 3104, 72231, 51766, 32812}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %q", tc.encoding, tc.text), func(t *testing.T) {
+			encoding := getEmbeddedEncoding(t, tc.encoding)
+
+			tokens := encoding.Encode(tc.text)
+			assert.Equal(t, tc.expected, tokens)
+
+			text, err := encoding.Decode(tokens)
+			require.NoError(t, err)
+			assert.Equal(t, tc.text, text)
+		})
+	}
+}
+
+var benchmarkText = strings.Repeat("The quick brown fox jumps over the lazy dog. It's 2024!\n", 100)
+
+func BenchmarkEncode(b *testing.B) {
+	encoding := newTestEncoding()
+
+	b.SetBytes(int64(len(benchmarkText)))
+	for i := 0; i < b.N; i++ {
+		encoding.Encode(benchmarkText)
+	}
+}
+
+func BenchmarkCountCL100K(b *testing.B) {
+	encoding := getEmbeddedEncoding(b, CL100KBase)
+
+	b.SetBytes(int64(len(benchmarkText)))
+	for i := 0; i < b.N; i++ {
+		encoding.Count(benchmarkText)
+	}
+}
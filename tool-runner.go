@@ -0,0 +1,257 @@
+package gopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	defaultToolRunnerMaxIterations  = 10
+	defaultToolRunnerMaxConcurrency = 4
+
+	toolErrorResultTpl = "error: %s"
+)
+
+var (
+	// ErrMaxIterationsExceeded is an error that indicates the model
+	// was still calling tools after the maximum number of iterations.
+	ErrMaxIterationsExceeded = errors.New("maximum number of iterations exceeded")
+	// ErrTokenBudgetExceeded is an error that indicates the model was
+	// still calling tools after the token budget was used up.
+	ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+	// ErrUnknownTool is an error that indicates the model
+	// called a tool that is not registered.
+	ErrUnknownTool = errors.New("unknown tool")
+)
+
+// ToolFunc executes a tool call with the arguments generated by the
+// model and returns the result that is sent back to the model.
+type ToolFunc func(ctx context.Context, arguments string) (string, error)
+
+type registeredTool struct {
+	definition ChatCompletionTool
+	fn         ToolFunc
+}
+
+// ToolRegistry holds the tools a ToolRunner can execute.
+// The zero value is ready to use.
+type ToolRegistry struct {
+	names []string
+	tools map[string]registeredTool
+}
+
+// Register adds a tool to the registry, replacing any
+// tool previously registered with the same name.
+func (r *ToolRegistry) Register(tool ChatCompletionTool, fn ToolFunc) {
+	if r.tools == nil {
+		r.tools = map[string]registeredTool{}
+	}
+
+	name := tool.Function.Name
+	if _, ok := r.tools[name]; !ok {
+		r.names = append(r.names, name)
+	}
+
+	r.tools[name] = registeredTool{definition: tool, fn: fn}
+}
+
+// Tools returns the definitions of the registered
+// tools, in the order they were registered.
+func (r *ToolRegistry) Tools() []ChatCompletionTool {
+	tools := make([]ChatCompletionTool, 0, len(r.names))
+	for _, name := range r.names {
+		tools = append(tools, r.tools[name].definition)
+	}
+
+	return tools
+}
+
+// RegisterTool adds a function tool whose parameters are described
+// by the JSON Schema generated from T. The arguments generated by the
+// model are validated and decoded into T before fn is called.
+func RegisterTool[T any](r *ToolRegistry, name, description string, fn func(context.Context, T) (string, error)) error {
+	var params T
+
+	tool, err := NewFunctionToolFor(name, description, params)
+	if err != nil {
+		return err
+	}
+
+	r.Register(tool, func(ctx context.Context, arguments string) (string, error) {
+		var params T
+		if err := DecodeToolArguments(arguments, &params); err != nil {
+			return "", err
+		}
+
+		return fn(ctx, params)
+	})
+
+	return nil
+}
+
+// ToolRunner runs the tool calling loop of an agent: it creates chat
+// completions, executes the tools the model calls and sends their results
+// back until the model responds without calling any tool.
+type ToolRunner struct {
+	// API is the API used to create the chat completions.
+	API ChatCompletionsAPI
+	// Registry holds the tools the model can call.
+	Registry *ToolRegistry
+	// MaxIterations is the maximum number of completions created.
+	// Defaults to 10.
+	MaxIterations int
+	// MaxTotalTokens is the maximum number of tokens used across all
+	// completions. Zero means no limit.
+	MaxTotalTokens int
+	// MaxConcurrency is the maximum number of tool calls of a single
+	// response executed in parallel. Defaults to 4.
+	MaxConcurrency int
+	// BeforeToolCall, if set, is called before each tool call. If it
+	// returns an error the tool is not executed and the error is sent
+	// back to the model as the tool's result.
+	BeforeToolCall func(ctx context.Context, toolCall ChatCompletionToolCall) error
+	// AfterToolCall, if set, is called after each tool call
+	// with its result and error.
+	AfterToolCall func(ctx context.Context, toolCall ChatCompletionToolCall, result string, err error)
+}
+
+// ToolRunResult is the outcome of a ToolRunner run.
+type ToolRunResult struct {
+	// Messages is the full transcript, starting with the messages
+	// of the params the run was started with.
+	Messages []ChatCompletionMessage
+	// Completion is the last completion created.
+	Completion ChatCompletion
+	// Usage is the token usage aggregated across all completions.
+	Usage TokenUsage
+	// Iterations is the number of completions created.
+	Iterations int
+}
+
+// Run runs the tool calling loop starting with the given params. If
+// params has no tools, the ones of the registry are used. Tool errors are
+// sent back to the model as the tool's result. It returns
+// ErrMaxIterationsExceeded or ErrTokenBudgetExceeded, along with the
+// result so far, when the model is still calling tools after the limits.
+func (r ToolRunner) Run(ctx context.Context, params ChatCompletionParams) (ToolRunResult, error) {
+	maxIterations := r.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultToolRunnerMaxIterations
+	}
+
+	registry := r.Registry
+	if registry == nil {
+		registry = &ToolRegistry{}
+	}
+
+	if len(params.Tools) == 0 {
+		params.Tools = registry.Tools()
+	}
+
+	result := ToolRunResult{
+		Messages: append([]ChatCompletionMessage(nil), params.Messages...),
+	}
+
+	for {
+		if result.Iterations >= maxIterations {
+			return result, ErrMaxIterationsExceeded
+		}
+
+		params.Messages = result.Messages
+
+		completion, err := r.API.CreateWithContext(ctx, params)
+		if err != nil {
+			return result, err
+		}
+
+		result.Iterations++
+		result.Completion = completion
+		result.Usage = result.Usage.add(completion.Usage)
+
+		if len(completion.Choices) == 0 {
+			return result, ErrNoChoices
+		}
+
+		message := completion.Choices[0].Message
+		result.Messages = append(result.Messages, message)
+
+		if len(message.ToolCalls) == 0 {
+			return result, nil
+		}
+
+		if r.MaxTotalTokens > 0 && result.Usage.TotalTokens >= r.MaxTotalTokens {
+			return result, ErrTokenBudgetExceeded
+		}
+
+		result.Messages = append(result.Messages, r.executeToolCalls(ctx, registry, message.ToolCalls)...)
+	}
+}
+
+// executeToolCalls executes the given tool calls in parallel and
+// returns the tool messages holding their results, in call order.
+func (r ToolRunner) executeToolCalls(ctx context.Context, registry *ToolRegistry, toolCalls []ChatCompletionToolCall) []ChatCompletionMessage {
+	maxConcurrency := r.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultToolRunnerMaxConcurrency
+	}
+
+	messages := make([]ChatCompletionMessage, len(toolCalls))
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, toolCall ChatCompletionToolCall) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			content, err := r.executeToolCall(ctx, registry, toolCall)
+			if err != nil {
+				content = fmt.Sprintf(toolErrorResultTpl, err)
+			}
+
+			messages[i] = ChatCompletionMessage{
+				Role:       ChatCompletionMessageRoleTool,
+				Content:    content,
+				ToolCallID: toolCall.ID,
+			}
+		}(i, toolCall)
+	}
+
+	wg.Wait()
+
+	return messages
+}
+
+func (r ToolRunner) executeToolCall(ctx context.Context, registry *ToolRegistry, toolCall ChatCompletionToolCall) (result string, err error) {
+	if r.BeforeToolCall != nil {
+		if err := r.BeforeToolCall(ctx, toolCall); err != nil {
+			return "", err
+		}
+	}
+
+	if r.AfterToolCall != nil {
+		defer func() {
+			r.AfterToolCall(ctx, toolCall, result, err)
+		}()
+	}
+
+	tool, ok := registry.tools[toolCall.Function.Name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTool, toolCall.Function.Name)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("tool %s panicked: %v", toolCall.Function.Name, p)
+		}
+	}()
+
+	return tool.fn(ctx, toolCall.Function.Arguments)
+}
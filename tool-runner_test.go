@@ -0,0 +1,144 @@
+package gopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddParams struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func newTestToolCallsCompletion(usage TokenUsage, toolCalls ...ChatCompletionToolCall) ChatCompletion {
+	completion := newTestChatCompletion(ChatCompletionMessage{ToolCalls: toolCalls}, "tool_calls")
+	completion.Usage = usage
+
+	return completion
+}
+
+func newTestToolCall(id, name, arguments string) ChatCompletionToolCall {
+	return ChatCompletionToolCall{
+		ID:       id,
+		Type:     ChatCompletionToolTypeFunction,
+		Function: ChatCompletionFunctionCall{Name: name, Arguments: arguments},
+	}
+}
+
+func TestToolRunnerRun(t *testing.T) {
+	registry := &ToolRegistry{}
+	require.NoError(t, RegisterTool(registry, "add", "Adds two numbers", func(_ context.Context, p testAddParams) (string, error) {
+		return fmt.Sprint(p.A + p.B), nil
+	}))
+
+	registry.Register(NewFunctionTool("fail", "Always fails", nil), func(context.Context, string) (string, error) {
+		return "", errors.New("boom")
+	})
+
+	api := &fakeChatCompletionsAPI{responses: []ChatCompletion{
+		newTestToolCallsCompletion(TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			newTestToolCall("call_1", "add", `{"a": 1, "b": 2}`),
+			newTestToolCall("call_2", "fail", `{}`),
+			newTestToolCall("call_3", "add", `{"a": "x"}`),
+			newTestToolCall("call_4", "missing", `{}`),
+		),
+		{
+			Choices: []ChatCompletionChoice{{
+				Message: ChatCompletionMessage{Role: ChatCompletionMessageRoleAssistant, Content: "1 + 2 = 3"},
+			}},
+			Usage: TokenUsage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25},
+		},
+	}}
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	runner := ToolRunner{
+		API:      api,
+		Registry: registry,
+		BeforeToolCall: func(_ context.Context, toolCall ChatCompletionToolCall) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, "before "+toolCall.ID)
+
+			return nil
+		},
+		AfterToolCall: func(_ context.Context, toolCall ChatCompletionToolCall, _ string, _ error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, "after "+toolCall.ID)
+		},
+	}
+
+	result, err := runner.Run(context.Background(), ChatCompletionParams{
+		Messages: []ChatCompletionMessage{{Role: ChatCompletionMessageRoleUser, Content: "1 + 2?"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Iterations)
+	assert.Equal(t, TokenUsage{PromptTokens: 30, CompletionTokens: 10, TotalTokens: 40}, result.Usage)
+	assert.Equal(t, "1 + 2 = 3", result.Completion.Choices[0].Message.Content)
+	assert.Len(t, api.requests[0].Tools, 2)
+	assert.Len(t, calls, 8)
+
+	require.Len(t, result.Messages, 7)
+	assert.Equal(t, ChatCompletionMessage{Role: ChatCompletionMessageRoleTool, Content: "3", ToolCallID: "call_1"}, result.Messages[2])
+	assert.Equal(t, "error: boom", result.Messages[3].Content)
+	assert.Contains(t, result.Messages[4].Content, "$.a: must be a number")
+	assert.Contains(t, result.Messages[5].Content, ErrUnknownTool.Error())
+	assert.Equal(t, "1 + 2 = 3", result.Messages[6].Content)
+	assert.Equal(t, result.Messages[:6], api.requests[1].Messages)
+}
+
+func TestToolRunnerRunLimits(t *testing.T) {
+	registry := &ToolRegistry{}
+	registry.Register(NewFunctionTool("noop", "", nil), func(context.Context, string) (string, error) {
+		return "ok", nil
+	})
+
+	testCases := []struct {
+		name               string
+		runner             ToolRunner
+		expectedErr        error
+		expectedIterations int
+	}{
+		{
+			name:               "max iterations",
+			runner:             ToolRunner{Registry: registry, MaxIterations: 2},
+			expectedErr:        ErrMaxIterationsExceeded,
+			expectedIterations: 2,
+		},
+		{
+			name:               "token budget",
+			runner:             ToolRunner{Registry: registry, MaxTotalTokens: 25},
+			expectedErr:        ErrTokenBudgetExceeded,
+			expectedIterations: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var responses []ChatCompletion
+			for i := 0; i < 5; i++ {
+				responses = append(responses, newTestToolCallsCompletion(TokenUsage{TotalTokens: 10},
+					newTestToolCall(fmt.Sprint(i), "noop", "{}")))
+			}
+
+			tc.runner.API = &fakeChatCompletionsAPI{responses: responses}
+
+			result, err := tc.runner.Run(context.Background(), ChatCompletionParams{})
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedIterations, result.Iterations)
+		})
+	}
+}
@@ -0,0 +1,585 @@
+package gopenai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/psyb0t/gopenai/tokenizer"
+)
+
+const (
+	defaultTrainingDataModel     = "gpt-4o-mini"
+	defaultTrainingDataEpochs    = 3
+	defaultTrainingDataSeparator = "\n\n###\n\n"
+	defaultTrainingDataStop      = " END"
+	maxTrainingDataLineSize      = 64 << 20
+
+	trainingDataKeyMessages   = "messages"
+	trainingDataKeyPrompt     = "prompt"
+	trainingDataKeyCompletion = "completion"
+)
+
+// trainingExampleTokenLimits holds the maximum number of tokens of
+// a training example of models, keyed by model name or model name
+// prefix. They are lower than the context windows of some models.
+var trainingExampleTokenLimits = map[string]int{
+	"gpt-4.1":            65536,
+	"gpt-4o":             65536,
+	"gpt-4-0613":         8192,
+	"gpt-3.5-turbo":      16385,
+	"gpt-3.5-turbo-0613": 4096,
+	"davinci-002":        16384,
+	"babbage-002":        16384,
+}
+
+// trainingExampleTokenLimitPrefixes holds the keys of
+// trainingExampleTokenLimits, longest first so that
+// the most specific prefix wins.
+var trainingExampleTokenLimitPrefixes = func() []string {
+	prefixes := make([]string, 0, len(trainingExampleTokenLimits))
+	for prefix := range trainingExampleTokenLimits {
+		prefixes = append(prefixes, prefix)
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+
+		return prefixes[i] < prefixes[j]
+	})
+
+	return prefixes
+}()
+
+// trainingExampleTokenLimit returns the maximum number of tokens
+// of a training example of the given model. It returns false if
+// the model is not known.
+func trainingExampleTokenLimit(model string) (int, bool) {
+	model = strings.TrimPrefix(model, fineTunedModelPrefix)
+	for _, prefix := range trainingExampleTokenLimitPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return trainingExampleTokenLimits[prefix], true
+		}
+	}
+
+	return 0, false
+}
+
+// TrainingDataFormat is an enum type representing
+// the format of the examples of a training file.
+type TrainingDataFormat string
+
+// TrainingDataFormat enum values
+const (
+	TrainingDataFormatChat             TrainingDataFormat = "chat"
+	TrainingDataFormatPromptCompletion TrainingDataFormat = "prompt_completion"
+)
+
+// TrainingDataIssueType is an enum type representing
+// the type of an issue found in a training file.
+type TrainingDataIssueType string
+
+// TrainingDataIssueType enum values
+const (
+	TrainingDataIssueMalformed             TrainingDataIssueType = "malformed"
+	TrainingDataIssueMissingKey            TrainingDataIssueType = "missing_key"
+	TrainingDataIssueMixedFormat           TrainingDataIssueType = "mixed_format"
+	TrainingDataIssueInvalidRole           TrainingDataIssueType = "invalid_role"
+	TrainingDataIssueMissingAssistant      TrainingDataIssueType = "missing_assistant"
+	TrainingDataIssueDuplicate             TrainingDataIssueType = "duplicate"
+	TrainingDataIssueTooManyTokens         TrainingDataIssueType = "too_many_tokens"
+	TrainingDataIssueInconsistentSeparator TrainingDataIssueType = "inconsistent_separator"
+	TrainingDataIssueInconsistentStop      TrainingDataIssueType = "inconsistent_stop"
+	TrainingDataIssueMissingWhitespace     TrainingDataIssueType = "missing_whitespace"
+)
+
+// TrainingDataIssue represents an issue found in a training file.
+type TrainingDataIssue struct {
+	// Line is the line of the example the issue was found
+	// in, starting at 1, or 0 for issues of the whole file.
+	Line int `json:"line"`
+	// Type is the type of the issue.
+	Type TrainingDataIssueType `json:"type"`
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// TrainingDataReport is the result of the validation of a training file.
+type TrainingDataReport struct {
+	// Format is the format of the examples.
+	Format TrainingDataFormat `json:"format"`
+	// Lines is the number of lines of the file.
+	Lines int `json:"lines"`
+	// Examples is the number of examples without any issue
+	// that would make the fine-tune reject or drop them.
+	Examples int `json:"examples"`
+	// Issues are the issues found in the file.
+	Issues []TrainingDataIssue `json:"issues,omitempty"`
+	// Warnings are the checks and estimates that could not be
+	// made, such as the token checks when the tokenizer of the
+	// model is not known.
+	Warnings []string `json:"warnings,omitempty"`
+	// Separator is the separator all the prompts end with, if any.
+	// It is only set for the prompt/completion format.
+	Separator string `json:"separator,omitempty"`
+	// Stop is the stop sequence all the completions end with, if
+	// any. It is only set for the prompt/completion format.
+	Stop string `json:"stop,omitempty"`
+	// Tokens is the number of tokens of the valid examples.
+	// It is 0 if the tokenizer of the model is not known.
+	Tokens int `json:"tokens"`
+	// EstimatedTrainingTokens is the number of tokens
+	// trained on over all the epochs.
+	EstimatedTrainingTokens int `json:"estimated_training_tokens"`
+	// EstimatedCost is the estimated cost of the training, computed
+	// from TrainingDataOptions.PricePerMillionTokens. It is nil if no
+	// price is given or if the tokenizer of the model is not known.
+	EstimatedCost *float64 `json:"estimated_cost,omitempty"`
+}
+
+// Valid returns whether no issue was found in the file.
+func (r TrainingDataReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// TrainingDataOptions holds the options of the
+// validation and preparation of training files.
+type TrainingDataOptions struct {
+	// Model is the model to fine-tune, whose tokenizer counts the
+	// tokens of the examples. Defaults to gpt-4o-mini.
+	Model string
+	// MaxTokens is the maximum number of tokens of an example.
+	// Defaults to the limit of the model for training examples,
+	// and examples are not checked against it if it is not known.
+	MaxTokens int
+	// Epochs is the number of epochs the estimates are
+	// computed for. Defaults to 3.
+	Epochs int
+	// PricePerMillionTokens is the price of training on a million
+	// tokens, which the estimated cost is computed from. The cost
+	// is not estimated if it is not set.
+	PricePerMillionTokens float64
+	// Separator is appended to the prompts when they do not end
+	// with a common separator. Defaults to "\n\n###\n\n".
+	Separator string
+	// Stop is appended to the completions when they do not end
+	// with a common stop sequence. Defaults to " END".
+	Stop string
+}
+
+func (o TrainingDataOptions) withDefaults() TrainingDataOptions {
+	if o.Model == "" {
+		o.Model = defaultTrainingDataModel
+	}
+
+	if o.MaxTokens <= 0 {
+		o.MaxTokens, _ = trainingExampleTokenLimit(o.Model)
+	}
+
+	if o.Epochs <= 0 {
+		o.Epochs = defaultTrainingDataEpochs
+	}
+
+	if o.Separator == "" {
+		o.Separator = defaultTrainingDataSeparator
+	}
+
+	if o.Stop == "" {
+		o.Stop = defaultTrainingDataStop
+	}
+
+	return o
+}
+
+// ValidateTrainingData validates a JSONL training file, either in the
+// chat format or in the legacy prompt/completion format. It reports
+// malformed lines, missing keys, duplicate examples and examples over
+// the token limit, and for the prompt/completion format, prompts and
+// completions that do not share a separator and a stop sequence. The
+// token checks and estimates are skipped with a warning in the report
+// if the tokenizer of the model is not known.
+func ValidateTrainingData(r io.Reader, opts TrainingDataOptions) (TrainingDataReport, error) {
+	report, _, err := newTrainingDataValidator(opts).validate(r)
+
+	return report, err
+}
+
+// PrepareTrainingData validates a JSONL training file like
+// ValidateTrainingData and writes a corrected file to w. Examples that
+// cannot be corrected, such as malformed, duplicate or too long ones,
+// are dropped. Prompts and completions are given a common separator,
+// stop sequence and leading whitespace. It returns the report of the
+// validation of the given file.
+func PrepareTrainingData(r io.Reader, w io.Writer, opts TrainingDataOptions) (TrainingDataReport, error) {
+	validator := newTrainingDataValidator(opts)
+
+	report, examples, err := validator.validate(r)
+	if err != nil {
+		return report, err
+	}
+
+	return report, validator.write(w, report, examples)
+}
+
+// trainingDataValidator validates training files. Its counter is
+// nil if the tokenizer of the model is not known, in which case the
+// token checks and estimates are skipped.
+type trainingDataValidator struct {
+	opts    TrainingDataOptions
+	counter *chatTokenCounter
+}
+
+func newTrainingDataValidator(opts TrainingDataOptions) trainingDataValidator {
+	opts = opts.withDefaults()
+
+	encoding, err := tokenizer.EncodingForModel(opts.Model)
+	if err != nil {
+		return trainingDataValidator{opts: opts}
+	}
+
+	return newTrainingDataValidatorWithEncoding(opts, encoding)
+}
+
+func newTrainingDataValidatorWithEncoding(opts TrainingDataOptions, encoding *tokenizer.Encoding) trainingDataValidator {
+	opts = opts.withDefaults()
+	counter := newChatTokenCounterWithEncoding(opts.Model, encoding)
+
+	return trainingDataValidator{
+		opts:    opts,
+		counter: &counter,
+	}
+}
+
+// warnings returns the checks and estimates the validator cannot make.
+func (v trainingDataValidator) warnings() []string {
+	if v.counter == nil {
+		return []string{fmt.Sprintf("tokenizer of model %s is not known, tokens are not counted", v.opts.Model)}
+	}
+
+	var warnings []string
+	if v.opts.MaxTokens <= 0 {
+		warnings = append(warnings, fmt.Sprintf("token limit of model %s is not known, examples are not checked against it", v.opts.Model))
+	}
+
+	if v.opts.PricePerMillionTokens <= 0 {
+		warnings = append(warnings, "no price per million tokens given, the cost is not estimated")
+	}
+
+	return warnings
+}
+
+// trainingExample is an example of a training file,
+// along with the fields its validation needs.
+type trainingExample struct {
+	line       int
+	fields     map[string]json.RawMessage
+	format     TrainingDataFormat
+	prompt     string
+	completion string
+	messages   []ChatCompletionMessage
+}
+
+// key returns the key identifying duplicate examples.
+func (e trainingExample) key() string {
+	if e.format == TrainingDataFormatPromptCompletion {
+		return e.prompt + "\x00" + e.completion
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, e.fields[trainingDataKeyMessages]); err != nil {
+		return string(e.fields[trainingDataKeyMessages])
+	}
+
+	return compacted.String()
+}
+
+// validate validates the training file and returns its
+// report along with the examples without any issue.
+func (v trainingDataValidator) validate(r io.Reader) (TrainingDataReport, []trainingExample, error) {
+	var (
+		report   TrainingDataReport
+		examples []trainingExample
+		seen     = map[string]int{}
+	)
+
+	issue := func(line int, issueType TrainingDataIssueType, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, TrainingDataIssue{
+			Line:    line,
+			Type:    issueType,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxTrainingDataLineSize)
+
+	for scanner.Scan() {
+		report.Lines++
+		line := report.Lines
+
+		example, issueType, message := v.parse(line, scanner.Bytes())
+
+		if report.Format == "" {
+			report.Format = example.format
+		}
+
+		if example.format != "" && example.format != report.Format {
+			issue(line, TrainingDataIssueMixedFormat, "example is in the %s format but the file is in the %s format", example.format, report.Format)
+
+			continue
+		}
+
+		if issueType != "" {
+			issue(line, issueType, "%s", message)
+
+			continue
+		}
+
+		key := example.key()
+		if first, ok := seen[key]; ok {
+			issue(line, TrainingDataIssueDuplicate, "example is a duplicate of line %d", first)
+
+			continue
+		}
+
+		seen[key] = line
+
+		if v.counter != nil {
+			tokens := v.countTokens(example)
+			if v.opts.MaxTokens > 0 && tokens > v.opts.MaxTokens {
+				issue(line, TrainingDataIssueTooManyTokens, "example has %d tokens, over the limit of %d", tokens, v.opts.MaxTokens)
+
+				continue
+			}
+
+			report.Tokens += tokens
+		}
+
+		examples = append(examples, example)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return TrainingDataReport{}, nil, err
+	}
+
+	report.Examples = len(examples)
+
+	if report.Format == TrainingDataFormatPromptCompletion && len(examples) > 1 {
+		prompts := make([]string, len(examples))
+		completions := make([]string, len(examples))
+		missingWhitespace := 0
+
+		for i, example := range examples {
+			prompts[i], completions[i] = example.prompt, example.completion
+			if !startsWithWhitespace(example.completion) {
+				missingWhitespace++
+			}
+		}
+
+		if report.Separator = commonSuffix(prompts); report.Separator == "" {
+			issue(0, TrainingDataIssueInconsistentSeparator, "prompts do not end with a common separator")
+		}
+
+		if report.Stop = commonSuffix(completions); report.Stop == "" {
+			issue(0, TrainingDataIssueInconsistentStop, "completions do not end with a common stop sequence")
+		}
+
+		if missingWhitespace > 0 {
+			issue(0, TrainingDataIssueMissingWhitespace, "%d completions do not start with whitespace", missingWhitespace)
+		}
+	}
+
+	report.Warnings = v.warnings()
+	report.EstimatedTrainingTokens = report.Tokens * v.opts.Epochs
+
+	if v.counter != nil && v.opts.PricePerMillionTokens > 0 {
+		report.EstimatedCost = Float64(float64(report.EstimatedTrainingTokens) / 1e6 * v.opts.PricePerMillionTokens)
+	}
+
+	return report, examples, nil
+}
+
+// parse parses a line of the training file. It returns the type and
+// the message of the issue that makes the example invalid, if any.
+func (v trainingDataValidator) parse(line int, data []byte) (trainingExample, TrainingDataIssueType, string) {
+	example := trainingExample{line: line}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return example, TrainingDataIssueMalformed, "line is empty"
+	}
+
+	if err := json.Unmarshal(data, &example.fields); err != nil || example.fields == nil {
+		return example, TrainingDataIssueMalformed, "line is not a JSON object"
+	}
+
+	if messages, ok := example.fields[trainingDataKeyMessages]; ok {
+		example.format = TrainingDataFormatChat
+
+		if err := json.Unmarshal(messages, &example.messages); err != nil {
+			return example, TrainingDataIssueMalformed, fmt.Sprintf("invalid messages: %s", err)
+		}
+
+		issueType, message := v.checkMessages(example.messages)
+
+		return example, issueType, message
+	}
+
+	_, hasPrompt := example.fields[trainingDataKeyPrompt]
+	_, hasCompletion := example.fields[trainingDataKeyCompletion]
+
+	switch {
+	case !hasPrompt && !hasCompletion:
+		return example, TrainingDataIssueMissingKey, `example has neither a "messages" key nor "prompt" and "completion" keys`
+	case !hasPrompt:
+		return example, TrainingDataIssueMissingKey, `example has no "prompt" key`
+	case !hasCompletion:
+		return example, TrainingDataIssueMissingKey, `example has no "completion" key`
+	}
+
+	example.format = TrainingDataFormatPromptCompletion
+
+	if err := json.Unmarshal(example.fields[trainingDataKeyPrompt], &example.prompt); err != nil {
+		return example, TrainingDataIssueMalformed, "prompt is not a string"
+	}
+
+	if err := json.Unmarshal(example.fields[trainingDataKeyCompletion], &example.completion); err != nil {
+		return example, TrainingDataIssueMalformed, "completion is not a string"
+	}
+
+	return example, "", ""
+}
+
+func (v trainingDataValidator) checkMessages(messages []ChatCompletionMessage) (TrainingDataIssueType, string) {
+	if len(messages) == 0 {
+		return TrainingDataIssueMissingKey, "messages are empty"
+	}
+
+	hasAssistant := false
+
+	for i, message := range messages {
+		switch message.Role {
+		case ChatCompletionMessageRoleAssistant:
+			hasAssistant = true
+		case ChatCompletionMessageRoleSystem, ChatCompletionMessageRoleUser, ChatCompletionMessageRoleTool:
+		default:
+			return TrainingDataIssueInvalidRole, fmt.Sprintf("message %d has invalid role %q", i, message.Role)
+		}
+	}
+
+	if !hasAssistant {
+		return TrainingDataIssueMissingAssistant, "messages have no assistant message"
+	}
+
+	return "", ""
+}
+
+func (v trainingDataValidator) countTokens(example trainingExample) int {
+	if example.format == TrainingDataFormatPromptCompletion {
+		return v.counter.encoding.Count(example.prompt) + v.counter.encoding.Count(example.completion)
+	}
+
+	count := replyPrimingTokens
+	for _, message := range example.messages {
+		count += v.counter.countMessage(message)
+	}
+
+	return count
+}
+
+// write writes the given examples, corrected as the report requires.
+func (v trainingDataValidator) write(w io.Writer, report TrainingDataReport, examples []trainingExample) error {
+	fixSeparator, fixStop := false, false
+	for _, issue := range report.Issues {
+		switch issue.Type {
+		case TrainingDataIssueInconsistentSeparator:
+			fixSeparator = true
+		case TrainingDataIssueInconsistentStop:
+			fixStop = true
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	for _, example := range examples {
+		if example.format == TrainingDataFormatPromptCompletion {
+			if fixSeparator && !strings.HasSuffix(example.prompt, v.opts.Separator) {
+				example.prompt += v.opts.Separator
+			}
+
+			if !startsWithWhitespace(example.completion) {
+				example.completion = " " + example.completion
+			}
+
+			if fixStop && !strings.HasSuffix(example.completion, v.opts.Stop) {
+				example.completion += v.opts.Stop
+			}
+
+			example.fields[trainingDataKeyPrompt] = marshalTrainingDataString(example.prompt)
+			example.fields[trainingDataKeyCompletion] = marshalTrainingDataString(example.completion)
+		}
+
+		if err := encoder.Encode(example.fields); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// marshalTrainingDataString marshals a string
+// without escaping its HTML characters.
+func marshalTrainingDataString(s string) json.RawMessage {
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+
+	return bytes.TrimSuffix(data.Bytes(), []byte("\n"))
+}
+
+// commonSuffix returns the longest suffix all the given
+// strings share, without splitting multi-byte runes.
+func commonSuffix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	suffix := values[0]
+	for _, value := range values[1:] {
+		n := 0
+		for n < len(suffix) && n < len(value) {
+			_, size := utf8.DecodeLastRuneInString(suffix[:len(suffix)-n])
+			_, valueSize := utf8.DecodeLastRuneInString(value[:len(value)-n])
+			if size != valueSize || suffix[len(suffix)-n-size:len(suffix)-n] != value[len(value)-n-size:len(value)-n] {
+				break
+			}
+
+			n += size
+		}
+
+		suffix = suffix[len(suffix)-n:]
+		if suffix == "" {
+			break
+		}
+	}
+
+	return suffix
+}
+
+func startsWithWhitespace(s string) bool {
+	for _, r := range s {
+		return unicode.IsSpace(r)
+	}
+
+	return false
+}
@@ -0,0 +1,268 @@
+package gopenai
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTrainingDataValidator(t *testing.T, opts TrainingDataOptions) trainingDataValidator {
+	t.Helper()
+
+	counter := newTestChatTokenCounter(t, opts.withDefaults().Model)
+
+	return newTrainingDataValidatorWithEncoding(opts, counter.encoding)
+}
+
+func TestTrainingDataValidatorValidate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           TrainingDataOptions
+		data           string
+		expectedReport TrainingDataReport
+	}{
+		{
+			name: "valid prompt/completion",
+			opts: TrainingDataOptions{PricePerMillionTokens: 1e6},
+			data: `{"prompt":"a ->","completion":" b\n"}` + "\n" +
+				`{"prompt":"cd ->","completion":" e\n"}` + "\n",
+			expectedReport: TrainingDataReport{
+				Format:                  TrainingDataFormatPromptCompletion,
+				Lines:                   2,
+				Examples:                2,
+				Separator:               " ->",
+				Stop:                    "\n",
+				Tokens:                  15,
+				EstimatedTrainingTokens: 45,
+				EstimatedCost:           Float64(45),
+			},
+		},
+		{
+			name: "invalid prompt/completion",
+			opts: TrainingDataOptions{MaxTokens: 10, Epochs: 1},
+			data: `{"prompt":"a","completion":"b."}` + "\n" +
+				"\n" +
+				`{"prompt":"a"` + "\n" +
+				`{"prompt":"c"}` + "\n" +
+				`{"prompt":1,"completion":"d"}` + "\n" +
+				`{"prompt":"a","completion":"b."}` + "\n" +
+				`{"prompt":"0123456789","completion":"x"}` + "\n" +
+				`{"messages":[{"role":"user","content":"a"}]}` + "\n" +
+				`{"text":"a"}` + "\n" +
+				`{"prompt":"c ","completion":" d!"}` + "\n",
+			expectedReport: TrainingDataReport{
+				Format:   TrainingDataFormatPromptCompletion,
+				Lines:    10,
+				Examples: 2,
+				Issues: []TrainingDataIssue{
+					{Line: 2, Type: TrainingDataIssueMalformed, Message: "line is empty"},
+					{Line: 3, Type: TrainingDataIssueMalformed, Message: "line is not a JSON object"},
+					{Line: 4, Type: TrainingDataIssueMissingKey, Message: `example has no "completion" key`},
+					{Line: 5, Type: TrainingDataIssueMalformed, Message: "prompt is not a string"},
+					{Line: 6, Type: TrainingDataIssueDuplicate, Message: "example is a duplicate of line 1"},
+					{Line: 7, Type: TrainingDataIssueTooManyTokens, Message: "example has 11 tokens, over the limit of 10"},
+					{Line: 8, Type: TrainingDataIssueMixedFormat, Message: "example is in the chat format but the file is in the prompt_completion format"},
+					{Line: 9, Type: TrainingDataIssueMissingKey, Message: `example has neither a "messages" key nor "prompt" and "completion" keys`},
+					{Line: 0, Type: TrainingDataIssueInconsistentSeparator, Message: "prompts do not end with a common separator"},
+					{Line: 0, Type: TrainingDataIssueInconsistentStop, Message: "completions do not end with a common stop sequence"},
+					{Line: 0, Type: TrainingDataIssueMissingWhitespace, Message: "1 completions do not start with whitespace"},
+				},
+				Warnings:                []string{"no price per million tokens given, the cost is not estimated"},
+				Tokens:                  8,
+				EstimatedTrainingTokens: 8,
+			},
+		},
+		{
+			name: "chat",
+			opts: TrainingDataOptions{Epochs: 2},
+			data: `{"messages":[{"role":"user","content":"a"},{"role":"assistant","content":"b"}]}` + "\n" +
+				`{"messages":[]}` + "\n" +
+				`{"messages":"a"}` + "\n" +
+				`{"messages":[{"role":"robot","content":"a"}]}` + "\n" +
+				`{"messages":[{"role":"user","content":"a"}]}` + "\n" +
+				`{"messages": [{"role":"user","content":"a"}, {"role":"assistant","content":"b"}]}` + "\n",
+			expectedReport: TrainingDataReport{
+				Format:   TrainingDataFormatChat,
+				Lines:    6,
+				Examples: 1,
+				Issues: []TrainingDataIssue{
+					{Line: 2, Type: TrainingDataIssueMissingKey, Message: "messages are empty"},
+					{Line: 3, Type: TrainingDataIssueMalformed, Message: "invalid messages: json: cannot unmarshal string into Go value of type []gopenai.ChatCompletionMessage"},
+					{Line: 4, Type: TrainingDataIssueInvalidRole, Message: `message 0 has invalid role "robot"`},
+					{Line: 5, Type: TrainingDataIssueMissingAssistant, Message: "messages have no assistant message"},
+					{Line: 6, Type: TrainingDataIssueDuplicate, Message: "example is a duplicate of line 1"},
+				},
+				Warnings:                []string{"no price per million tokens given, the cost is not estimated"},
+				Tokens:                  24,
+				EstimatedTrainingTokens: 48,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			validator := newTestTrainingDataValidator(t, testCase.opts)
+
+			report, examples, err := validator.validate(strings.NewReader(testCase.data))
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedReport, report)
+			assert.Len(t, examples, testCase.expectedReport.Examples)
+			assert.Equal(t, len(testCase.expectedReport.Issues) == 0, report.Valid())
+		})
+	}
+}
+
+func TestTrainingDataValidatorWrite(t *testing.T) {
+	testCases := []struct {
+		name         string
+		data         string
+		expectedData string
+	}{
+		{
+			name: "fixes prompt/completion",
+			data: `{"prompt":"a","completion":"b","weight":1}` + "\n" +
+				`{"prompt":"c\n\n###\n\n","completion":" d END"}` + "\n" +
+				`{"prompt":"a","completion":"b","weight":1}` + "\n" +
+				"not json\n",
+			expectedData: `{"completion":" b END","prompt":"a\n\n###\n\n","weight":1}` + "\n" +
+				`{"completion":" d END","prompt":"c\n\n###\n\n"}` + "\n",
+		},
+		{
+			name: "keeps consistent prompt/completion",
+			data: `{"prompt":"a ->","completion":" <b>"}` + "\n" +
+				`{"prompt":"c ->","completion":"d>"}` + "\n",
+			expectedData: `{"completion":" <b>","prompt":"a ->"}` + "\n" +
+				`{"completion":" d>","prompt":"c ->"}` + "\n",
+		},
+		{
+			name: "drops invalid chat examples",
+			data: `{"messages":[{"role":"user","content":"a"},{"role":"assistant","content":"b"}]}` + "\n" +
+				`{"messages":[{"role":"user","content":"a"}]}` + "\n",
+			expectedData: `{"messages":[{"role":"user","content":"a"},{"role":"assistant","content":"b"}]}` + "\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			validator := newTestTrainingDataValidator(t, TrainingDataOptions{})
+
+			report, examples, err := validator.validate(strings.NewReader(testCase.data))
+			require.NoError(t, err)
+
+			output := &bytes.Buffer{}
+			require.NoError(t, validator.write(output, report, examples))
+			assert.Equal(t, testCase.expectedData, output.String())
+
+			fixed, _, err := validator.validate(strings.NewReader(output.String()))
+			require.NoError(t, err)
+			assert.True(t, fixed.Valid(), fixed.Issues)
+		})
+	}
+}
+
+func TestValidateTrainingData(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		{Role: ChatCompletionMessageRoleSystem, Content: "You are a helpful assistant."},
+		{Role: ChatCompletionMessageRoleUser, Content: "What is the capital of France?"},
+		{Role: ChatCompletionMessageRoleAssistant, Content: "Paris."},
+	}
+
+	example, err := json.Marshal(map[string]interface{}{"messages": messages})
+	require.NoError(t, err)
+
+	data := string(example) + "\n" + string(example) + "\n"
+
+	tokens, err := CountChatTokens("gpt-4o-mini", messages)
+	require.NoError(t, err)
+
+	report, err := ValidateTrainingData(strings.NewReader(data), TrainingDataOptions{
+		Model:                 "gpt-4o-mini-2024-07-18",
+		PricePerMillionTokens: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TrainingDataReport{
+		Format:   TrainingDataFormatChat,
+		Lines:    2,
+		Examples: 1,
+		Issues: []TrainingDataIssue{
+			{Line: 2, Type: TrainingDataIssueDuplicate, Message: "example is a duplicate of line 1"},
+		},
+		Tokens:                  tokens,
+		EstimatedTrainingTokens: 3 * tokens,
+		EstimatedCost:           Float64(float64(3*tokens) / 1e6 * 3),
+	}, report)
+
+	report, err = ValidateTrainingData(strings.NewReader(data), TrainingDataOptions{
+		Model:                 "my-model",
+		PricePerMillionTokens: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TrainingDataReport{
+		Format:   TrainingDataFormatChat,
+		Lines:    2,
+		Examples: 1,
+		Issues: []TrainingDataIssue{
+			{Line: 2, Type: TrainingDataIssueDuplicate, Message: "example is a duplicate of line 1"},
+		},
+		Warnings: []string{"tokenizer of model my-model is not known, tokens are not counted"},
+	}, report)
+}
+
+func TestPrepareTrainingData(t *testing.T) {
+	data := `{"prompt":"Capital of France","completion":"Paris"}` + "\n" +
+		`{"prompt":"Capital of Spain?","completion":" Madrid"}` + "\n" +
+		`{"prompt":"Capital of Spain?"}` + "\n"
+
+	output := &bytes.Buffer{}
+
+	report, err := PrepareTrainingData(strings.NewReader(data), output, TrainingDataOptions{Model: "davinci-002"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Examples)
+	assert.Len(t, report.Issues, 4)
+	assert.Equal(t, `{"completion":" Paris END","prompt":"Capital of France\n\n###\n\n"}`+"\n"+
+		`{"completion":" Madrid END","prompt":"Capital of Spain?\n\n###\n\n"}`+"\n", output.String())
+
+	report, err = ValidateTrainingData(output, TrainingDataOptions{Model: "davinci-002", PricePerMillionTokens: 6})
+	require.NoError(t, err)
+	assert.True(t, report.Valid(), report.Issues)
+	assert.Equal(t, "\n\n###\n\n", report.Separator)
+	assert.Equal(t, " END", report.Stop)
+	assert.Empty(t, report.Warnings)
+	assert.Positive(t, report.Tokens)
+}
+
+func TestTrainingExampleTokenLimit(t *testing.T) {
+	testCases := []struct {
+		model      string
+		expected   int
+		expectedOK bool
+	}{
+		{model: "gpt-4o-mini-2024-07-18", expected: 65536, expectedOK: true},
+		{model: "ft:gpt-4o-2024-08-06:org::abc123", expected: 65536, expectedOK: true},
+		{model: "gpt-3.5-turbo-0125", expected: 16385, expectedOK: true},
+		{model: "gpt-3.5-turbo-0613", expected: 4096, expectedOK: true},
+		{model: "gpt-4-0613", expected: 8192, expectedOK: true},
+		{model: "davinci", expected: 0, expectedOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.model, func(t *testing.T) {
+			limit, ok := trainingExampleTokenLimit(tc.model)
+			assert.Equal(t, tc.expected, limit)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestCommonSuffix(t *testing.T) {
+	assert.Equal(t, "", commonSuffix(nil))
+	assert.Equal(t, "abc", commonSuffix([]string{"abc"}))
+	assert.Equal(t, "\n###\n", commonSuffix([]string{"a\n###\n", "bc\n###\n", "\n###\n"}))
+	assert.Equal(t, "", commonSuffix([]string{"ab", "ba"}))
+	assert.Equal(t, "", commonSuffix([]string{"aé", "b©"}))
+	assert.Equal(t, "→", commonSuffix([]string{"a→", "b→"}))
+}
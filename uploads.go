@@ -0,0 +1,489 @@
+package gopenai
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	uploadsAPIEndpoint = "/uploads"
+
+	defaultUploadPartSize     = 64 << 20
+	defaultUploadConcurrency  = 4
+	defaultUploadPartAttempts = 3
+)
+
+var (
+	// ErrUnknownUploadSize is returned by UploadLarge when the size
+	// of the input is neither given nor readable from the reader.
+	ErrUnknownUploadSize = errors.New("unknown upload size")
+	// ErrUploadSizeMismatch is returned by UploadLarge when the
+	// input is not as long as the size of the upload.
+	ErrUploadSizeMismatch = errors.New("input size does not match the upload size")
+	// ErrUploadIncomplete is returned by UploadLarge when
+	// a completed upload does not hold a file.
+	ErrUploadIncomplete = errors.New("completed upload has no file")
+)
+
+// UploadStatus is an enum type representing the status of an upload.
+type UploadStatus string
+
+// UploadStatus enum values
+const (
+	UploadStatusPending   UploadStatus = "pending"
+	UploadStatusCompleted UploadStatus = "completed"
+	UploadStatusCancelled UploadStatus = "cancelled"
+	UploadStatusExpired   UploadStatus = "expired"
+)
+
+// Upload represents an upload that files larger than the size limit
+// of FilesAPI.Create are sent through, in multiple parts.
+type Upload struct {
+	// ID is the identifier of the upload.
+	ID string `json:"id"`
+	// Object is the object type, which is always "upload".
+	Object string `json:"object"`
+	// Bytes is the size of the file being uploaded.
+	Bytes int64 `json:"bytes"`
+	// CreatedAt is the UNIX timestamp of when the upload was created.
+	CreatedAt int `json:"created_at"`
+	// ExpiresAt is the UNIX timestamp of when the upload expires.
+	ExpiresAt int `json:"expires_at"`
+	// Filename is the name of the file being uploaded.
+	Filename string `json:"filename"`
+	// Purpose is the purpose of the file being uploaded.
+	Purpose string `json:"purpose"`
+	// Status is the status of the upload.
+	Status UploadStatus `json:"status"`
+	// File is the file created by completing the upload.
+	File *File `json:"file"`
+}
+
+// UploadParams represents the parameters for creating an upload.
+type UploadParams struct {
+	// Filename is the name of the file to upload.
+	Filename string `json:"filename"`
+	// Purpose is the purpose of the file to upload.
+	Purpose string `json:"purpose"`
+	// Bytes is the size of the file to upload.
+	Bytes int64 `json:"bytes"`
+	// MimeType is the MIME type of the file to upload.
+	MimeType string `json:"mime_type"`
+}
+
+// UploadPart represents a part of the file added to an upload.
+type UploadPart struct {
+	// ID is the identifier of the part.
+	ID string `json:"id"`
+	// Object is the object type, which is always "upload.part".
+	Object string `json:"object"`
+	// CreatedAt is the UNIX timestamp of when the part was created.
+	CreatedAt int `json:"created_at"`
+	// UploadID is the identifier of the upload the part belongs to.
+	UploadID string `json:"upload_id"`
+}
+
+// UploadCompleteParams represents the parameters for completing an upload.
+type UploadCompleteParams struct {
+	// PartIDs are the identifiers of the parts, in the order
+	// their content is assembled in.
+	PartIDs []string `json:"part_ids"`
+	// MD5 is the hex encoded MD5 checksum of the file, which the
+	// API verifies against the assembled parts when set.
+	MD5 string `json:"md5,omitempty"`
+}
+
+// UploadLargeOptions holds the options of UploadsAPI.UploadLarge.
+type UploadLargeOptions struct {
+	// Filename is the name of the file. It defaults to the base name
+	// of the file when the reader is an *os.File.
+	Filename string
+	// MimeType is the MIME type of the file. Defaults
+	// to application/octet-stream.
+	MimeType string
+	// Size is the size of the input. It is read from the reader when
+	// left 0 and the reader is an *os.File, whose input starts at its
+	// current offset, or has a Len method.
+	Size int64
+	// PartSize is the size of the parts. Defaults to 64MB,
+	// which is the maximum size the API accepts.
+	PartSize int64
+	// Concurrency is the maximum number of parts uploaded,
+	// and held in memory, at once. Defaults to 4.
+	Concurrency int
+	// PartAttempts is the maximum number of attempts at uploading
+	// each part, including the first one. Defaults to 3. It replaces
+	// RetryPolicy.MaxAttempts of the client for the parts, which
+	// are retried on timeouts, connection errors and retryable
+	// status codes, with the backoff of the client's RetryPolicy or
+	// the delay asked for by the server, capped by its MaxBackoff. Its
+	// OnRetry is called for the retries of parts, possibly concurrently.
+	PartAttempts int
+	// VerifyMD5 makes the API verify the MD5 checksum of the input
+	// against the assembled parts when completing the upload.
+	VerifyMD5 bool
+}
+
+func (o UploadLargeOptions) withDefaults(r io.Reader) (UploadLargeOptions, error) {
+	file, isFile := r.(*os.File)
+
+	if o.Filename == "" {
+		if !isFile {
+			return o, errors.New("upload filename is required")
+		}
+
+		o.Filename = path.Base(file.Name())
+	}
+
+	if o.MimeType == "" {
+		o.MimeType = "application/octet-stream"
+	}
+
+	if o.Size <= 0 {
+		switch r := r.(type) {
+		case *os.File:
+			info, err := r.Stat()
+			if err != nil {
+				return o, err
+			}
+
+			// the input starts at the current offset of the file
+			offset, err := r.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return o, err
+			}
+
+			o.Size = info.Size() - offset
+		case interface{ Len() int }:
+			o.Size = int64(r.Len())
+		default:
+			return o, ErrUnknownUploadSize
+		}
+	}
+
+	if o.PartSize <= 0 {
+		o.PartSize = defaultUploadPartSize
+	}
+
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultUploadConcurrency
+	}
+
+	if o.PartAttempts <= 0 {
+		o.PartAttempts = defaultUploadPartAttempts
+	}
+
+	return o, nil
+}
+
+// UploadsAPI is the interface for uploading files in multiple parts.
+type UploadsAPI interface {
+	// Create creates an upload that parts can be added to.
+	Create(UploadParams) (Upload, error)
+	// CreateWithContext is like Create but uses the given context.
+	CreateWithContext(context.Context, UploadParams) (Upload, error)
+	// AddPart adds a part, read from data, to an upload.
+	AddPart(uploadID string, data io.Reader) (UploadPart, error)
+	// AddPartWithContext is like AddPart but uses the given context.
+	AddPartWithContext(ctx context.Context, uploadID string, data io.Reader) (UploadPart, error)
+	// Complete completes an upload, creating its file out of its parts.
+	Complete(uploadID string, params UploadCompleteParams) (Upload, error)
+	// CompleteWithContext is like Complete but uses the given context.
+	CompleteWithContext(ctx context.Context, uploadID string, params UploadCompleteParams) (Upload, error)
+	// Cancel cancels an upload.
+	Cancel(uploadID string) (Upload, error)
+	// CancelWithContext is like Cancel but uses the given context.
+	CancelWithContext(ctx context.Context, uploadID string) (Upload, error)
+	// UploadLarge uploads the content of r as a file with the given
+	// purpose. The content is split into parts which are uploaded in
+	// parallel and retried on failure. The upload is cancelled if any
+	// part cannot be uploaded.
+	UploadLarge(ctx context.Context, r io.Reader, purpose string, opts UploadLargeOptions) (File, error)
+}
+
+type uploadsAPI struct {
+	c client
+}
+
+func (api uploadsAPI) Create(params UploadParams) (Upload, error) {
+	return api.CreateWithContext(context.Background(), params)
+}
+
+func (api uploadsAPI) CreateWithContext(ctx context.Context, params UploadParams) (Upload, error) {
+	url := api.c.endpointURL(uploadsAPIEndpoint)
+
+	return api.upload(ctx, url, params)
+}
+
+func (api uploadsAPI) AddPart(uploadID string, data io.Reader) (UploadPart, error) {
+	return api.AddPartWithContext(context.Background(), uploadID, data)
+}
+
+func (api uploadsAPI) AddPartWithContext(ctx context.Context, uploadID string, data io.Reader) (UploadPart, error) {
+	url := api.c.endpointURL(uploadsAPIEndpoint, uploadID, "parts")
+	params := struct {
+		Data *FileUpload `mapstructure:"data"`
+	}{
+		Data: NewFileUpload(data, "part", ""),
+	}
+
+	body, contentType, err := structToMultipartBody(params)
+	if err != nil {
+		return UploadPart{}, err
+	}
+
+	r, err := api.c.getRequestResponse(ctx, url, http.MethodPost, body, contentType)
+	if err != nil {
+		return UploadPart{}, err
+	}
+
+	var response UploadPart
+	if err := json.Unmarshal(r, &response); err != nil {
+		return UploadPart{}, err
+	}
+
+	return response, nil
+}
+
+func (api uploadsAPI) Complete(uploadID string, params UploadCompleteParams) (Upload, error) {
+	return api.CompleteWithContext(context.Background(), uploadID, params)
+}
+
+func (api uploadsAPI) CompleteWithContext(ctx context.Context, uploadID string, params UploadCompleteParams) (Upload, error) {
+	url := api.c.endpointURL(uploadsAPIEndpoint, uploadID, "complete")
+
+	return api.upload(ctx, url, params)
+}
+
+func (api uploadsAPI) Cancel(uploadID string) (Upload, error) {
+	return api.CancelWithContext(context.Background(), uploadID)
+}
+
+func (api uploadsAPI) CancelWithContext(ctx context.Context, uploadID string) (Upload, error) {
+	url := api.c.endpointURL(uploadsAPIEndpoint, uploadID, "cancel")
+
+	return api.upload(ctx, url, nil)
+}
+
+func (api uploadsAPI) upload(ctx context.Context, url string, params interface{}) (Upload, error) {
+	r, err := api.c.getJSONRequestResponse(ctx, url, http.MethodPost, params)
+	if err != nil {
+		return Upload{}, err
+	}
+
+	var response Upload
+	if err := json.Unmarshal(r, &response); err != nil {
+		return Upload{}, err
+	}
+
+	return response, nil
+}
+
+func (api uploadsAPI) UploadLarge(ctx context.Context, r io.Reader, purpose string, opts UploadLargeOptions) (File, error) {
+	opts, err := opts.withDefaults(r)
+	if err != nil {
+		return File{}, err
+	}
+
+	upload, err := api.CreateWithContext(ctx, UploadParams{
+		Filename: opts.Filename,
+		Purpose:  purpose,
+		Bytes:    opts.Size,
+		MimeType: opts.MimeType,
+	})
+	if err != nil {
+		return File{}, err
+	}
+
+	params, err := api.addParts(ctx, upload.ID, r, opts)
+	if err != nil {
+		// the upload is cancelled even if ctx is done
+		_, _ = api.CancelWithContext(context.Background(), upload.ID)
+
+		return File{}, err
+	}
+
+	upload, err = api.CompleteWithContext(ctx, upload.ID, params)
+	if err != nil {
+		return File{}, err
+	}
+
+	if upload.File == nil {
+		return File{}, ErrUploadIncomplete
+	}
+
+	return *upload.File, nil
+}
+
+// addParts uploads the content of r as parts of the given upload and
+// returns the parameters completing it. The parts are read one after
+// the other and uploaded by up to opts.Concurrency goroutines.
+func (api uploadsAPI) addParts(ctx context.Context, uploadID string, r io.Reader, opts UploadLargeOptions) (UploadCompleteParams, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hash := md5.New() //nolint:gosec
+	if opts.VerifyMD5 {
+		r = io.TeeReader(r, hash)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		read     int64
+	)
+
+	// an empty input is completed with an empty list rather than null
+	partIDs := []string{}
+
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for index := 0; read < opts.Size; index++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		data := make([]byte, min64(opts.PartSize, opts.Size-read))
+		n, err := io.ReadFull(r, data)
+		read += int64(n)
+
+		if err != nil {
+			<-sem
+			if err == io.ErrUnexpectedEOF || err == io.EOF {
+				err = fmt.Errorf("%w: expected %d bytes, got %d", ErrUploadSizeMismatch, opts.Size, read)
+			}
+
+			setErr(err)
+
+			break
+		}
+
+		mu.Lock()
+		partIDs = append(partIDs, "")
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(index int, data []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			part, err := api.addPartWithRetries(ctx, uploadID, data, opts.PartAttempts)
+			if err != nil {
+				setErr(err)
+
+				return
+			}
+
+			mu.Lock()
+			partIDs[index] = part.ID
+			mu.Unlock()
+		}(index, data)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return UploadCompleteParams{}, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return UploadCompleteParams{}, requestError(ctx, err)
+	}
+
+	if n, _ := r.Read(make([]byte, 1)); n > 0 {
+		return UploadCompleteParams{}, fmt.Errorf("%w: input is longer than %d bytes", ErrUploadSizeMismatch, opts.Size)
+	}
+
+	params := UploadCompleteParams{PartIDs: partIDs}
+	if opts.VerifyMD5 {
+		params.MD5 = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return params, nil
+}
+
+// addPartWithRetries adds a part to an upload, retrying up to the given
+// number of attempts. The parts are only retried here, the requests of
+// the single attempts are sent without the retries of the client.
+func (api uploadsAPI) addPartWithRetries(ctx context.Context, uploadID string, data []byte, attempts int) (UploadPart, error) {
+	policy := api.c.cfg.RetryPolicy.withDefaults()
+
+	single := api
+	single.c.cfg.RetryPolicy.MaxAttempts = 1
+
+	for attempt := 1; ; attempt++ {
+		part, err := single.AddPartWithContext(ctx, uploadID, bytes.NewReader(data))
+		if err == nil || attempt >= attempts || !isRetryablePartError(policy, err) {
+			return part, err
+		}
+
+		var header http.Header
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			header = apiErr.Header
+		}
+
+		delay := policy.retryDelay(attempt, header)
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryEvent{Attempt: attempt, Delay: delay, Err: err})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return UploadPart{}, requestError(ctx, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryablePartError reports whether a part is retried after failing
+// with err: responses with a retryable status code are, as are timeouts
+// and connection errors. Any other error, including a cancelled or
+// expired context, fails the part.
+func isRetryablePartError(policy RetryPolicy, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return policy.shouldRetry(&http.Response{StatusCode: apiErr.StatusCode}, nil)
+	}
+
+	return policy.shouldRetry(nil, err)
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,324 @@
+package gopenai
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUploadsServer struct {
+	mu          sync.Mutex
+	parts       map[string]string
+	failures    map[string]int
+	created     UploadParams
+	completed   UploadCompleteParams
+	completeRaw string
+	cancelled   bool
+	partStatus  int
+	failContent string
+	failAlways  bool
+	failHeader  http.Header
+}
+
+func newTestUploadsServer(t *testing.T, s *testUploadsServer) *httptest.Server {
+	t.Helper()
+
+	s.parts = map[string]string{}
+	s.failures = map[string]int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+
+		switch {
+		case r.URL.Path == "/uploads":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&s.created))
+			_, _ = io.WriteString(w, `{"id": "upload-1", "status": "pending"}`)
+		case strings.HasSuffix(r.URL.Path, "/parts"):
+			file, _, err := r.FormFile("data")
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+
+			if string(content) == s.failContent && (s.failAlways || s.failures[s.failContent] < 1) {
+				s.failures[s.failContent]++
+				for k, values := range s.failHeader {
+					w.Header()[k] = values
+				}
+
+				w.WriteHeader(s.partStatus)
+				_, _ = io.WriteString(w, `{"error": {"message": "failed"}}`)
+
+				return
+			}
+
+			id := fmt.Sprintf("part-%d", len(s.parts))
+			s.parts[id] = string(content)
+			_, _ = io.WriteString(w, `{"id": "`+id+`", "upload_id": "upload-1"}`)
+		case strings.HasSuffix(r.URL.Path, "/complete"):
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			s.completeRaw = string(body)
+			require.NoError(t, json.Unmarshal(body, &s.completed))
+			_, _ = io.WriteString(w, `{"id": "upload-1", "status": "completed", "file": {"id": "file-1", "bytes": 10}}`)
+		case strings.HasSuffix(r.URL.Path, "/cancel"):
+			s.cancelled = true
+			_, _ = io.WriteString(w, `{"id": "upload-1", "status": "cancelled"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *testUploadsServer) assembled() string {
+	var content string
+	for _, id := range s.completed.PartIDs {
+		content += s.parts[id]
+	}
+
+	return content
+}
+
+func TestUploadsUploadLarge(t *testing.T) {
+	s := &testUploadsServer{partStatus: http.StatusInternalServerError, failContent: "def"}
+	server := newTestUploadsServer(t, s)
+
+	client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{BaseBackoff: time.Millisecond}})
+	file, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader("abcdefghij"), "fine-tune", UploadLargeOptions{
+		Filename:    "train.jsonl",
+		PartSize:    3,
+		Concurrency: 2,
+		VerifyMD5:   true,
+	})
+	require.NoError(t, err)
+
+	sum := md5.Sum([]byte("abcdefghij")) //nolint:gosec
+
+	assert.Equal(t, "file-1", file.ID)
+	assert.Equal(t, UploadParams{Filename: "train.jsonl", Purpose: "fine-tune", Bytes: 10, MimeType: "application/octet-stream"}, s.created)
+	assert.Len(t, s.completed.PartIDs, 4)
+	assert.Equal(t, "abcdefghij", s.assembled())
+	assert.Equal(t, hex.EncodeToString(sum[:]), s.completed.MD5)
+	assert.Equal(t, 1, s.failures["def"])
+	assert.False(t, s.cancelled)
+}
+
+func TestUploadsUploadLargeCancelsOnFailure(t *testing.T) {
+	s := &testUploadsServer{partStatus: http.StatusBadRequest, failContent: "def"}
+	server := newTestUploadsServer(t, s)
+
+	client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{BaseBackoff: time.Millisecond}})
+	_, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader("abcdefghij"), "fine-tune", UploadLargeOptions{
+		Filename: "train.jsonl",
+		PartSize: 3,
+	})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, 1, s.failures["def"])
+	assert.True(t, s.cancelled)
+}
+
+func TestUploadsUploadLargeSizeMismatch(t *testing.T) {
+	s := &testUploadsServer{}
+	server := newTestUploadsServer(t, s)
+
+	client := New(Config{BaseURL: server.URL})
+	_, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader("abcdefghij"), "fine-tune", UploadLargeOptions{
+		Filename: "train.jsonl",
+		Size:     12,
+		PartSize: 3,
+	})
+	assert.ErrorIs(t, err, ErrUploadSizeMismatch)
+	assert.True(t, s.cancelled)
+
+	_, err = client.Uploads().UploadLarge(context.Background(), io.MultiReader(strings.NewReader("abc")), "fine-tune", UploadLargeOptions{
+		Filename: "train.jsonl",
+	})
+	assert.ErrorIs(t, err, ErrUnknownUploadSize)
+}
+
+func TestUploadsUploadLargePartAttempts(t *testing.T) {
+	s := &testUploadsServer{partStatus: http.StatusInternalServerError, failContent: "def", failAlways: true}
+	server := newTestUploadsServer(t, s)
+
+	var retries int
+
+	client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{
+		MaxAttempts: 5,
+		BaseBackoff: time.Millisecond,
+		OnRetry:     func(RetryEvent) { retries++ },
+	}})
+	_, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader("abcdefghij"), "fine-tune", UploadLargeOptions{
+		Filename:     "train.jsonl",
+		PartSize:     3,
+		Concurrency:  1,
+		PartAttempts: 2,
+	})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, 2, s.failures["def"])
+	assert.Equal(t, 1, retries)
+	assert.True(t, s.cancelled)
+}
+
+func TestUploadsUploadLargePartRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		header        http.Header
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "server delay",
+			header:        http.Header{headerNameRetryAfterMs: []string{"5"}},
+			expectedDelay: time.Millisecond * 5,
+		},
+		{
+			name:          "server delay capped",
+			header:        http.Header{headerNameRetryAfter: []string{"60"}},
+			expectedDelay: time.Millisecond * 20,
+		},
+		{
+			name:          "backoff without server delay",
+			header:        http.Header{},
+			expectedDelay: time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &testUploadsServer{partStatus: http.StatusTooManyRequests, failContent: "def", failHeader: tc.header}
+			server := newTestUploadsServer(t, s)
+
+			var (
+				mu     sync.Mutex
+				events []RetryEvent
+			)
+
+			client := New(Config{BaseURL: server.URL, RetryPolicy: RetryPolicy{
+				BaseBackoff: time.Millisecond,
+				MaxBackoff:  time.Millisecond * 20,
+				OnRetry: func(e RetryEvent) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					events = append(events, e)
+				},
+			}})
+			_, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader("abcdefghij"), "fine-tune", UploadLargeOptions{
+				Filename: "train.jsonl",
+				PartSize: 3,
+			})
+			require.NoError(t, err)
+
+			require.Len(t, events, 1)
+			assert.Equal(t, tc.expectedDelay, events[0].Delay)
+			assert.Equal(t, "abcdefghij", s.assembled())
+		})
+	}
+}
+
+func TestUploadsUploadLargeFileOffset(t *testing.T) {
+	s := &testUploadsServer{}
+	server := newTestUploadsServer(t, s)
+
+	filePath := filepath.Join(t.TempDir(), "train.jsonl")
+	require.NoError(t, os.WriteFile(filePath, []byte("skipabcdefghij"), 0o600))
+
+	f, err := os.Open(filePath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+
+	_, err = New(Config{BaseURL: server.URL}).Uploads().UploadLarge(context.Background(), f, "fine-tune", UploadLargeOptions{PartSize: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, "train.jsonl", s.created.Filename)
+	assert.Equal(t, int64(10), s.created.Bytes)
+	assert.Equal(t, "abcdefghij", s.assembled())
+}
+
+func TestIsRetryablePartError(t *testing.T) {
+	policy := RetryPolicy{}.withDefaults()
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "retryable status", err: &APIError{StatusCode: http.StatusServiceUnavailable}, expected: true},
+		{name: "client error status", err: &APIError{StatusCode: http.StatusBadRequest}},
+		{name: "timeout", err: ErrRequestTimeout, expected: true},
+		{name: "connection error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, expected: true},
+		{name: "canceled", err: ErrRequestCanceled},
+		{name: "deadline exceeded", err: ErrRequestDeadlineExceeded},
+		{name: "decoding error", err: &json.SyntaxError{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isRetryablePartError(policy, tc.err))
+		})
+	}
+}
+
+func TestUploadsUploadLargeEmpty(t *testing.T) {
+	s := &testUploadsServer{}
+	server := newTestUploadsServer(t, s)
+
+	client := New(Config{BaseURL: server.URL})
+	_, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader(""), "fine-tune", UploadLargeOptions{
+		Filename: "train.jsonl",
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"part_ids": []}`, s.completeRaw)
+	assert.Empty(t, s.parts)
+}
+
+func TestUploadsUploadLargeRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/parts"):
+			time.Sleep(time.Millisecond * 200)
+			_, _ = io.WriteString(w, `{"id": "part-1", "upload_id": "upload-1"}`)
+		default:
+			_, _ = io.WriteString(w, `{"id": "upload-1", "status": "pending"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := New(Config{BaseURL: server.URL, RequestTimeout: time.Millisecond * 50})
+	_, err := client.Uploads().UploadLarge(context.Background(), strings.NewReader("abc"), "fine-tune", UploadLargeOptions{
+		Filename:     "train.jsonl",
+		PartAttempts: 1,
+	})
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+}
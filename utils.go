@@ -1,51 +1,185 @@
 package gopenai
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/textproto"
 	"os"
-	"path"
+	"strings"
 
 	"github.com/mitchellh/mapstructure"
 )
 
-func structToMultipartFormData(v interface{}) (*bytes.Buffer, string, error) {
-	data := &bytes.Buffer{}
-	writer := multipart.NewWriter(data)
-
+// structToMultipartBody returns a body streaming the fields of v as
+// multipart form data, along with its content type. Fields holding a
+// *FileUpload are sent as files and the others as their string value.
+func structToMultipartBody(v interface{}) (bodyFunc, string, error) {
 	structMap := map[string]interface{}{}
 	if err := mapstructure.Decode(v, &structMap); err != nil {
 		return nil, "", err
 	}
 
+	body := &multipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+		fields:   map[string]string{},
+		uploads:  map[string]*uploadState{},
+	}
+
 	for k, v := range structMap {
-		strVal := fmt.Sprintf("%v", v)
-		if (k == "image" || k == "mask" || k == "file") && strVal != "" {
-			file, err := os.Open(strVal)
-			if err != nil {
-				return nil, "", err
+		if upload, ok := v.(*FileUpload); ok {
+			if upload != nil {
+				body.uploads[k] = &uploadState{upload: upload}
 			}
 
-			part, err := writer.CreateFormFile(k, path.Base(strVal))
-			if err != nil {
-				return nil, "", err
-			}
+			continue
+		}
 
-			if _, err := io.Copy(part, file); err != nil {
-				return nil, "", err
-			}
+		body.fields[k] = fmt.Sprintf("%v", v)
+	}
 
-			continue
+	contentType := "multipart/form-data; boundary=" + body.boundary
+
+	return body.reader, contentType, nil
+}
+
+// multipartBody streams multipart form data through a pipe,
+// so that uploaded files are never fully buffered in memory.
+type multipartBody struct {
+	boundary string
+	fields   map[string]string
+	uploads  map[string]*uploadState
+
+	// pipe and done belong to the previous attempt of the request.
+	pipe *io.PipeReader
+	done chan struct{}
+}
+
+// reader returns the reader of the body for a new attempt of the
+// request, after stopping the writer of the previous attempt.
+func (b *multipartBody) reader() (io.Reader, error) {
+	if b.pipe != nil {
+		b.pipe.Close()
+		<-b.done
+	}
+
+	files := make(map[string]io.ReadCloser, len(b.uploads))
+	for k, state := range b.uploads {
+		file, err := state.open()
+		if err != nil {
+			closeAll(files)
+
+			return nil, err
 		}
 
-		if err := writer.WriteField(k, strVal); err != nil {
-			return nil, "", err
+		files[k] = file
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer closeAll(files)
+
+		pw.CloseWithError(b.write(pw, files))
+	}()
+
+	b.pipe, b.done = pr, done
+
+	return pr, nil
+}
+
+func (b *multipartBody) write(w io.Writer, files map[string]io.ReadCloser) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for k, file := range files {
+		upload := b.uploads[k].upload
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(k), escapeQuotes(upload.Filename)))
+		header.Set(headerNameContentType, upload.contentType())
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = file
+		if upload.OnProgress != nil {
+			r = newProgressReader(file, 0, upload.size(file), upload.OnProgress)
+		}
+
+		if _, err := io.Copy(part, r); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range b.fields {
+		if err := writer.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// uploadState tracks the reads of a FileUpload
+// across the attempts of a request.
+type uploadState struct {
+	upload *FileUpload
+	// offset is the offset the reader is rewound to before a retry.
+	offset int64
+	// read is whether an attempt of the request has read from the reader.
+	read bool
+}
+
+// open returns the reader of the file content for a new attempt of the
+// request, rewinding the reader if a previous attempt has read from it.
+func (s *uploadState) open() (io.ReadCloser, error) {
+	if s.upload.path != "" {
+		return os.Open(s.upload.path)
+	}
+
+	if s.upload.Reader == nil {
+		return nil, fmt.Errorf("file upload %s has no reader", s.upload.Filename)
+	}
+
+	seeker, isSeeker := s.upload.Reader.(io.Seeker)
+
+	switch {
+	case !s.read && isSeeker:
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+
+		s.offset = offset
+	case s.read && !isSeeker:
+		return nil, ErrUploadNotRewindable
+	case s.read:
+		if _, err := seeker.Seek(s.offset, io.SeekStart); err != nil {
+			return nil, err
 		}
 	}
 
-	writer.Close()
+	s.read = true
+
+	return io.NopCloser(s.upload.Reader), nil
+}
+
+func closeAll(files map[string]io.ReadCloser) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
 
-	return data, writer.FormDataContentType(), nil
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
 }
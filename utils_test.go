@@ -1,73 +1,261 @@
 package gopenai
 
 import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testStruct struct {
-	File  string `mapstructure:"file,omitempty"`
-	Field string `mapstructure:"field,omitempty"`
+	File  *FileUpload `mapstructure:"file,omitempty"`
+	Field string      `mapstructure:"field,omitempty"`
 }
 
-func TestStructToMultipartFormData(t *testing.T) {
+type testPart struct {
+	filename    string
+	contentType string
+	content     string
+}
+
+func readMultipartBody(t *testing.T, body bodyFunc, contentType string) map[string]testPart {
+	t.Helper()
+
+	r, err := body()
+	require.NoError(t, err)
+
+	_, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+
+	parts := map[string]testPart{}
+	reader := multipart.NewReader(r, params["boundary"])
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		parts[part.FormName()] = testPart{
+			filename:    part.FileName(),
+			contentType: part.Header.Get(headerNameContentType),
+			content:     string(content),
+		}
+	}
+
+	return parts
+}
+
+func TestStructToMultipartBody(t *testing.T) {
 	testCases := []struct {
-		name                 string
-		input                interface{}
-		expectedDataContains []string
-		expectedContentType  string
-		expectErr            bool
+		name          string
+		input         interface{}
+		expectedParts map[string]testPart
+		expectErr     bool
 	}{
 		{
-			name: "inputs properly included",
+			name: "file from path",
 			input: testStruct{
-				File:  "./.fixture/test-file.jsonl",
+				File:  NewFileUploadFromPath("./.fixture/test-file.jsonl"),
 				Field: "test",
 			},
-			expectedDataContains: []string{
-				`Content-Disposition: form-data; name="file"; filename="test-file.jsonl"`,
-				`Content-Type: application/octet-stream`,
-				`{"prompt": "this is a prompt", "completion": "this is a completion"}`,
-				`Content-Disposition: form-data; name="field"`,
-				`test`,
+			expectedParts: map[string]testPart{
+				"file": {
+					filename:    "test-file.jsonl",
+					contentType: "application/octet-stream",
+					content:     `{"prompt": "this is a prompt", "completion": "this is a completion"}` + "\n",
+				},
+				"field": {content: "test"},
 			},
-			expectedContentType: "multipart/form-data",
-			expectErr:           false,
+		},
+		{
+			name: "file from reader",
+			input: testStruct{
+				File:  NewFileUpload(strings.NewReader("content"), "file.txt", "text/plain"),
+				Field: "test",
+			},
+			expectedParts: map[string]testPart{
+				"file":  {filename: "file.txt", contentType: "text/plain", content: "content"},
+				"field": {content: "test"},
+			},
+		},
+		{
+			name:          "no file",
+			input:         testStruct{Field: "test"},
+			expectedParts: map[string]testPart{"field": {content: "test"}},
 		},
 		{
 			name: "invalid file path",
 			input: testStruct{
-				File:  "/invalid/path/to/file.txt",
+				File:  NewFileUploadFromPath("/invalid/path/to/file.txt"),
 				Field: "Test",
 			},
-			expectedDataContains: nil,
-			expectedContentType:  "",
-			expectErr:            true,
+			expectErr: true,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			data, contentType, err := structToMultipartFormData(tc.input)
-			dataStr := data.String()
+			body, contentType, err := structToMultipartBody(tc.input)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(contentType, "multipart/form-data"))
 
-			dataContainsExpectedStrings := true
-			for _, expectedStr := range tc.expectedDataContains {
-				if !strings.Contains(dataStr, expectedStr) {
-					dataContainsExpectedStrings = false
+			if tc.expectErr {
+				_, err := body()
+				assert.Error(t, err)
 
-					break
-				}
+				return
 			}
 
-			assert.True(t, dataContainsExpectedStrings)
-			assert.True(t, strings.HasPrefix(contentType, tc.expectedContentType))
-
-			if tc.expectErr {
-				assert.NotNil(t, err)
-			}
+			assert.Equal(t, tc.expectedParts, readMultipartBody(t, body, contentType))
 		})
 	}
 }
+
+func TestStructToMultipartBodyRetry(t *testing.T) {
+	t.Run("seekable reader is rewound", func(t *testing.T) {
+		reader := strings.NewReader("xxcontent")
+		_, _ = reader.Seek(2, io.SeekStart)
+
+		body, contentType, err := structToMultipartBody(testStruct{File: NewFileUpload(reader, "file.txt", "")})
+		require.NoError(t, err)
+
+		first := readMultipartBody(t, body, contentType)
+		second := readMultipartBody(t, body, contentType)
+		assert.Equal(t, "content", first["file"].content)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("non seekable reader", func(t *testing.T) {
+		reader := io.MultiReader(bytes.NewReader([]byte("content")))
+
+		body, contentType, err := structToMultipartBody(testStruct{File: NewFileUpload(reader, "file.txt", "")})
+		require.NoError(t, err)
+
+		assert.Equal(t, "content", readMultipartBody(t, body, contentType)["file"].content)
+
+		_, err = body()
+		assert.ErrorIs(t, err, ErrUploadNotRewindable)
+	})
+}
+
+func TestFilesCreateRetriesUpload(t *testing.T) {
+	var contents []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+
+		contents = append(contents, string(content))
+		if len(contents) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, `{"id": "file-1", "filename": "data.jsonl"}`)
+	}))
+	defer server.Close()
+
+	client := New(Config{
+		BaseURL:     server.URL,
+		RetryPolicy: RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+	})
+
+	file, err := client.Files().Create(FileParams{
+		File:    NewFileUpload(strings.NewReader("data"), "data.jsonl", ""),
+		Purpose: "fine-tune",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "file-1", file.ID)
+	assert.Equal(t, []string{"data", "data"}, contents)
+}
+
+func TestFilesCreateNotRewindable(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		attempts++
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, `{"error": {"message": "overloaded"}}`)
+	}))
+	defer server.Close()
+
+	client := New(Config{
+		BaseURL:     server.URL,
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Hour},
+	})
+
+	start := time.Now()
+	_, err := client.Files().Create(FileParams{
+		File:    NewFileUpload(io.MultiReader(strings.NewReader("data")), "data.jsonl", ""),
+		Purpose: "fine-tune",
+	})
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Equal(t, 1, attempts)
+	assert.ErrorIs(t, err, ErrUploadNotRewindable)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, "overloaded", apiErr.Message)
+}
+
+// slowReader returns its chunks one per read after a delay.
+type slowReader struct {
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+
+	time.Sleep(r.delay)
+
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+
+	return n, nil
+}
+
+func TestFilesCreateRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		w.Header().Set(headerNameContentType, contentTypeJSON)
+		_, _ = io.WriteString(w, `{"id": "file-1"}`)
+	}))
+	defer server.Close()
+
+	client := New(Config{BaseURL: server.URL, RequestTimeout: time.Millisecond * 50})
+
+	// the upload takes longer than the request timeout
+	reader := &slowReader{chunks: []string{"a", "b", "c", "d", "e"}, delay: time.Millisecond * 40}
+
+	_, err := client.Files().Create(FileParams{
+		File:    NewFileUpload(reader, "data.jsonl", ""),
+		Purpose: "fine-tune",
+	})
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+}